@@ -0,0 +1,172 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// alertWebhookTimeout default timeout of posting alerts to webhook
+const alertWebhookTimeout = 10 * time.Second
+
+// alertCooldownDefault default cooldown of alerts
+const alertCooldownDefault = time.Minute
+
+// Alert fired when records of a level exceed the threshold within the window
+type Alert struct {
+	Level     string    `json:"level"`
+	Threshold int       `json:"threshold"`
+	Window    string    `json:"window"`  // like "1m0s"
+	Time      time.Time `json:"time"`    // time of the record fires the alert
+	Message   string    `json:"message"` // message of the record fires the alert
+}
+
+// AlertOptions threshold alerter options
+type AlertOptions struct {
+	// Levels counted separately, ABNORMAL and ERROR if empty
+	Levels LevelSet
+	// Threshold fire when the count of records exceeds it within the window
+	Threshold int
+	// Window sliding window of counting
+	Window time.Duration
+	// Cooldown the alert of a level is not fired again within it, 1m by default
+	Cooldown time.Duration
+	// OnAlert called when the alert fires, by the goroutine logs the record, it can log
+	OnAlert func(Alert)
+	// OnError called with errors of posting to webhook, printed to stderr if nil
+	OnError func(error)
+	// WebhookURL the alert is posted as JSON to it if set
+	WebhookURL string
+	// HTTPClient client of webhook, a client with 10s timeout if nil
+	HTTPClient *http.Client
+}
+
+// Alerter threshold alerter of records, register it to logger by Logger.AddAlerter
+type Alerter struct {
+	options AlertOptions
+	client  *http.Client
+
+	lock  sync.Mutex
+	times map[int][]time.Time // times of the last records by level, at most threshold+1
+	fired map[int]time.Time   // time of the last alert by level
+	posts sync.WaitGroup
+}
+
+// NewAlerter create threshold alerter
+func NewAlerter(options AlertOptions) *Alerter {
+	if options.Levels == 0 {
+		options.Levels = NewLevelSet(ABNORMAL, ERROR)
+	}
+	if options.Threshold < 0 {
+		options.Threshold = 0
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = alertCooldownDefault
+	}
+	client := options.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: alertWebhookTimeout}
+	}
+	return &Alerter{
+		options: options,
+		client:  client,
+		times:   make(map[int][]time.Time),
+		fired:   make(map[int]time.Time),
+	}
+}
+
+// Levels levels counted by the alerter
+func (a *Alerter) Levels() LevelSet {
+	return a.options.Levels
+}
+
+// Hook count the record, fire the alert if the count exceeds the threshold
+func (a *Alerter) Hook(r *Record) {
+	if !a.options.Levels.Contains(r.level) {
+		return
+	}
+	now := r.timestamp
+	a.lock.Lock()
+	times := append(a.times[r.level], now)
+	if len(times) > a.options.Threshold+1 {
+		times = times[len(times)-a.options.Threshold-1:]
+	}
+	a.times[r.level] = times
+	exceeded := len(times) > a.options.Threshold && now.Sub(times[0]) < a.options.Window
+	if exceeded {
+		if last, ok := a.fired[r.level]; ok && now.Sub(last) < a.options.Cooldown {
+			exceeded = false
+		} else {
+			a.fired[r.level] = now
+		}
+	}
+	a.lock.Unlock()
+	if !exceeded {
+		return
+	}
+
+	alert := Alert{
+		Level:     LevelFlags[r.level],
+		Threshold: a.options.Threshold,
+		Window:    a.options.Window.String(),
+		Time:      now,
+		Message:   r.msg,
+	}
+	if a.options.OnAlert != nil {
+		a.options.OnAlert(alert)
+	}
+	if a.options.WebhookURL != "" {
+		a.posts.Add(1)
+		go func() {
+			defer a.posts.Done()
+			if err := a.post(alert); err != nil {
+				a.postError(err)
+			}
+		}()
+	}
+}
+
+// post the alert to webhook
+func (a *Alerter) post(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Post(a.options.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// postError report the error of posting to webhook
+func (a *Alerter) postError(err error) {
+	if a.options.OnError != nil {
+		a.options.OnError(err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[go-log] alert webhook err: %v\n", err)
+}
+
+// Wait block until alerts posting to webhook are done
+func (a *Alerter) Wait() {
+	a.posts.Wait()
+}
+
+// AddAlerter register the alerter as a hook of its levels
+func (l *Logger) AddAlerter(a *Alerter) {
+	l.AddHook(a.Levels(), a.Hook)
+}
+
+// AddAlerter register the alerter to the default logger
+func AddAlerter(a *Alerter) {
+	loggerDefault.AddAlerter(a)
+}
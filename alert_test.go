@@ -0,0 +1,158 @@
+package golog_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+func Test_LoggerHook(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	var msgs []string
+	l.AddHook(golog.NewLevelSet(golog.ERROR), func(r *golog.Record) {
+		msgs = append(msgs, r.Msg())
+	})
+	l.Error("e1")
+	l.Common("c1")
+	l.Error("e2")
+	o.Len() // sync
+
+	if len(msgs) != 2 || msgs[0] != "e1" || msgs[1] != "e2" {
+		t.Errorf("hook got %v", msgs)
+	}
+}
+
+func Test_Alerter(t *testing.T) {
+	l, _ := gologtest.NewLogger(t)
+	clock := gologtest.NewFakeClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	l.SetClock(clock)
+
+	var alerts []golog.Alert
+	l.AddAlerter(golog.NewAlerter(golog.AlertOptions{
+		Threshold: 2,
+		Window:    time.Minute,
+		Cooldown:  10 * time.Minute,
+		OnAlert:   func(a golog.Alert) { alerts = append(alerts, a) },
+	}))
+
+	l.Error("e1")
+	l.Error("e2")
+	l.Abnormal("a1")
+	clock.Add(2 * time.Minute)
+	l.Error("e3")
+	l.Error("e4")
+	l.Error("e5") // 3 within the window, fires
+	l.Error("e6") // cooldown
+	clock.Add(11 * time.Minute)
+	l.Error("e7")
+	l.Error("e8")
+	l.Error("e9") // fires after cooldown
+	l.Sync()
+
+	if len(alerts) != 2 {
+		t.Fatalf("got %d alerts, want 2: %v", len(alerts), alerts)
+	}
+	if alerts[0].Level != golog.LevelFlagError || alerts[0].Message != "e5" || alerts[0].Window != "1m0s" {
+		t.Errorf("alert got %+v", alerts[0])
+	}
+	if alerts[1].Message != "e9" {
+		t.Errorf("alert after cooldown got %+v", alerts[1])
+	}
+}
+
+func Test_AlerterWebhook(t *testing.T) {
+	posted := make(chan golog.Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a golog.Alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Error(err)
+		}
+		posted <- a
+	}))
+	defer server.Close()
+
+	l, _ := gologtest.NewLogger(t)
+	alerter := golog.NewAlerter(golog.AlertOptions{Threshold: 1, Window: time.Minute, WebhookURL: server.URL})
+	l.AddAlerter(alerter)
+	l.Abnormal("slow")
+	l.Abnormal("slower")
+	l.Sync()
+	alerter.Wait()
+
+	select {
+	case a := <-posted:
+		if a.Level != golog.LevelFlagAbnormal || a.Threshold != 1 || a.Message != "slower" {
+			t.Errorf("posted alert got %+v", a)
+		}
+	default:
+		t.Error("no alert posted")
+	}
+}
+
+func Test_AlerterDefaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	l, _ := gologtest.NewLogger(t)
+	var alerts []golog.Alert
+	errs := make(chan error, 4)
+	alerter := golog.NewAlerter(golog.AlertOptions{
+		Threshold:  1,
+		Window:     time.Minute,
+		WebhookURL: server.URL,
+		OnAlert:    func(a golog.Alert) { alerts = append(alerts, a) },
+		OnError:    func(err error) { errs <- err },
+	})
+	l.AddAlerter(alerter)
+	for i := 0; i < 4; i++ {
+		l.Error("e%d", i)
+	}
+	l.Sync()
+	alerter.Wait()
+
+	if len(alerts) != 1 {
+		t.Errorf("got %d alerts within the default cooldown, want 1", len(alerts))
+	}
+	select {
+	case err := <-errs:
+		if err.Error() != "webhook status 500" {
+			t.Errorf("webhook err = %v", err)
+		}
+	default:
+		t.Error("webhook err not reported")
+	}
+}
+
+func Test_HookLogs(t *testing.T) {
+	l := golog.NewLoggerWithChanSize(1)
+	defer l.Close()
+	l.AddAlerter(golog.NewAlerter(golog.AlertOptions{
+		Threshold: 1,
+		Window:    time.Minute,
+		OnAlert: func(a golog.Alert) {
+			for i := 0; i < 8; i++ {
+				l.Common("alert %s", a.Message)
+			}
+		},
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Error("e1")
+		l.Error("e2")
+		l.Sync()
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("logging in the alert callback deadlocked")
+	}
+}
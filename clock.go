@@ -0,0 +1,63 @@
+package golog
+
+import (
+	"time"
+)
+
+// Clock provide the current time, replaced by a fake clock in tests to control the time
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock clock of the system time, default clock of logger and file writer
+var SystemClock Clock = systemClock{}
+
+// LoadLocation load location by name, "" for nil which means the clock's location,
+// "Local" for time.Local, "UTC" for time.UTC, others by time.LoadLocation
+func LoadLocation(name string) (*time.Location, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case "Local":
+		return time.Local, nil
+	default:
+		return time.LoadLocation(name)
+	}
+}
+
+// clockOrSystem return the clock, or SystemClock if not set
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+// startOfDay start of the day of t in its location
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// startOfHour start of the hour of t in its location
+func startOfHour(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+}
+
+// startOfSecond start of the second of t
+func startOfSecond(t time.Time) time.Time {
+	return t.Truncate(time.Second)
+}
+
+// startOfMinute start of the minute of t in its location
+func startOfMinute(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, t.Location())
+}
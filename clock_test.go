@@ -0,0 +1,185 @@
+package golog_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+// newRotateFileWriter create file writer under a temp dir with the fake clock, registered to a new logger
+func newRotateFileWriter(t *testing.T, clock *gologtest.FakeClock, options golog.FileWriterOptions) (*golog.Logger, *golog.FileWriter, string) {
+	dir := t.TempDir()
+	options.Filename = filepath.Join(dir, options.Filename)
+	options.Rotate = true
+	w := golog.NewFileWriterWithOptions(options)
+	w.SetClock(clock)
+	l := golog.NewLoggerWithChanSize(0)
+	l.SetClock(clock)
+	l.SetLayout("2006-01-02 15:04:05")
+	l.Register(w)
+	t.Cleanup(l.Close)
+	return l, w, dir
+}
+
+func logFiles(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range matches {
+		matches[i] = filepath.Base(matches[i])
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func assertLogFiles(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	got := logFiles(t, dir)
+	if len(got) != len(want) {
+		t.Fatalf("log files got %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("log files got %v, want %v", got, want)
+		}
+	}
+}
+
+func Test_FileWriterRotateDailyAtMonthEnd(t *testing.T) {
+	clock := gologtest.NewFakeClock(time.Date(2026, 1, 31, 23, 59, 0, 0, time.Local))
+	l, w, dir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename: "app-%Y%M%D.log",
+		Daily:    true,
+		MaxDays:  1,
+	})
+	l.Common("before midnight")
+
+	clock.Add(time.Minute * 2)
+	l.Sync()
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	l.Common("after midnight")
+	l.Sync()
+	assertLogFiles(t, dir, "app-20260131.log", "app-20260201.log")
+
+	cnt, err := ioutil.ReadFile(filepath.Join(dir, "app-20260201.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2026-02-01 00:01:00 [COMMON] "; string(cnt[:len(want)]) != want {
+		t.Errorf("record of new file got %q, want prefix %q", cnt, want)
+	}
+}
+
+func Test_FileWriterRotateEveryMaxDays(t *testing.T) {
+	clock := gologtest.NewFakeClock(time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local))
+	_, w, dir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename: "app-%Y%M%D.log",
+		Daily:    true,
+		MaxDays:  3,
+	})
+
+	for day := 0; day < 7; day++ {
+		clock.Add(time.Hour * 24)
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertLogFiles(t, dir, "app-20260301.log", "app-20260304.log", "app-20260307.log")
+
+	// rotate at the next check after the process missed the rotation day
+	clock.Add(time.Hour * 24 * 5)
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	assertLogFiles(t, dir, "app-20260301.log", "app-20260304.log", "app-20260307.log", "app-20260313.log")
+}
+
+func Test_FileWriterRotateHourlyAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 2026-03-08 02:00 EST jumps to 03:00 EDT
+	clock := gologtest.NewFakeClock(time.Date(2026, 3, 8, 1, 30, 0, 0, loc))
+	_, w, dir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename: "app-%Y%M%D%H.log",
+		Hourly:   true,
+		MaxHours: 1,
+	})
+
+	clock.Add(time.Hour)
+	if err = w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	assertLogFiles(t, dir, "app-2026030801.log", "app-2026030803.log")
+}
+
+func Test_FileWriterRotateMinutely(t *testing.T) {
+	clock := gologtest.NewFakeClock(time.Date(2026, 12, 31, 23, 58, 30, 0, time.Local))
+	_, w, dir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename:   "app-%Y%M%D%H%m.log",
+		Minutely:   true,
+		MaxMinutes: 1,
+	})
+
+	for i := 0; i < 3; i++ {
+		clock.Add(time.Second * 20)
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertLogFiles(t, dir, "app-202612312358.log", "app-202612312359.log")
+
+	clock.Add(time.Second * 30)
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	assertLogFiles(t, dir, "app-202612312358.log", "app-202612312359.log", "app-202701010000.log")
+}
+
+func Test_LoggerLocation(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	clock := gologtest.NewFakeClock(time.Date(2026, 10, 18, 8, 30, 0, 0, shanghai))
+	l, o := gologtest.NewLogger(t)
+	l.SetClock(clock)
+	l.SetLayout(golog.TimestampLayout)
+
+	l.Common("clock location")
+	l.SetLocation(time.UTC)
+	l.Common("utc")
+	ny, err := golog.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	l.SetLocation(ny)
+	l.Common("new york")
+
+	want := []string{
+		"2026-10-18T08:30:00.000+0800",
+		"2026-10-18T00:30:00.000+0000",
+		"2026-10-17T20:30:00.000-0400",
+	}
+	for i, r := range o.Records() {
+		if r.Time() != want[i] {
+			t.Errorf("record %d time got %s, want %s", i, r.Time(), want[i])
+		}
+	}
+}
+
+func Test_FileWriterLocation(t *testing.T) {
+	// 2026-10-18 01:00 in +0800 is still 2026-10-17 in UTC
+	clock := gologtest.NewFakeClock(time.Date(2026, 10, 18, 1, 0, 0, 0, time.FixedZone("CST", 8*3600)))
+	_, _, dir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename: "app-%Y%M%D%H.log",
+		Location: "UTC",
+	})
+	assertLogFiles(t, dir, "app-2026101717.log")
+}
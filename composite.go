@@ -0,0 +1,386 @@
+package golog
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failoverCheckIntervalDefault default interval of health checks of failed writers
+const failoverCheckIntervalDefault = 30 * time.Second
+
+// errCompositePathPattern path pattern is set on children of composite writers
+var errCompositePathPattern = errors.New("[go-log] path pattern is not supported by composite writer, set it on children")
+
+// HealthChecker writer reports it's healthy or not, used by failover writer to switch back
+type HealthChecker interface {
+	Health() error
+}
+
+// writerErrorList errors of children writers
+type writerErrorList []error
+
+func (l writerErrorList) Error() string {
+	s := make([]string, 0, len(l))
+	for _, err := range l {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+// joinErrors nil if no error, the error itself if only one
+func joinErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+	return writerErrorList(errs)
+}
+
+// levelsOf levels of writer, all levels if it is not a Leveler
+func levelsOf(w Writer) LevelSet {
+	if lw, ok := w.(Leveler); ok {
+		return lw.Levels()
+	}
+	return LevelSetAll
+}
+
+// initChildren init all children
+func initChildren(writers []Writer) error {
+	for _, w := range writers {
+		if err := w.Init(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushChildren flush all children which are Flusher
+func flushChildren(writers []Writer) error {
+	var errs []error
+	for _, w := range writers {
+		if f, ok := w.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// rotateChildren rotate all children which are Rotater
+func rotateChildren(writers []Writer) error {
+	var errs []error
+	for _, w := range writers {
+		if r, ok := w.(Rotater); ok {
+			if err := r.Rotate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// closeChildren close all children which are Closer
+func closeChildren(writers []Writer) error {
+	var errs []error
+	for _, w := range writers {
+		if c, ok := w.(Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return joinErrors(errs)
+}
+
+// levelsOfChildren union of levels of children
+func levelsOfChildren(writers []Writer) LevelSet {
+	var levels LevelSet
+	for _, w := range writers {
+		levels |= levelsOf(w)
+	}
+	return levels
+}
+
+// TeeWriter writes records to all children, each filters records by its own levels
+type TeeWriter struct {
+	writers []Writer
+}
+
+// Tee create tee writer of writers, wrap a child by LevelFilter for its own levels
+func Tee(writers ...Writer) *TeeWriter {
+	return &TeeWriter{writers: writers}
+}
+
+// Init init all children
+func (t *TeeWriter) Init() error {
+	return initChildren(t.writers)
+}
+
+// Write write the record to all children, a failed child doesn't stop others
+func (t *TeeWriter) Write(r *Record) error {
+	var errs []error
+	for _, w := range t.writers {
+		if err := w.Write(r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
+
+// Flush flush all children
+func (t *TeeWriter) Flush() error {
+	return flushChildren(t.writers)
+}
+
+// Rotate rotate all children
+func (t *TeeWriter) Rotate() error {
+	return rotateChildren(t.writers)
+}
+
+// SetPathPattern not supported, set it on children
+func (t *TeeWriter) SetPathPattern(string) error {
+	return errCompositePathPattern
+}
+
+// Close close all children
+func (t *TeeWriter) Close() error {
+	return closeChildren(t.writers)
+}
+
+// Levels union of levels of children
+func (t *TeeWriter) Levels() LevelSet {
+	return levelsOfChildren(t.writers)
+}
+
+// FilterWriter writes records accepted by the predicate to the writer
+type FilterWriter struct {
+	writer    Writer
+	predicate func(*Record) bool
+	levels    LevelSet
+}
+
+// Filter create filter writer of the writer by the predicate
+func Filter(w Writer, predicate func(*Record) bool) *FilterWriter {
+	return &FilterWriter{writer: w, predicate: predicate, levels: levelsOf(w)}
+}
+
+// LevelFilter create filter writer of the writer accepts records of levels
+func LevelFilter(w Writer, levels LevelSet) *FilterWriter {
+	f := Filter(w, func(r *Record) bool { return levels.Contains(r.level) })
+	f.levels &= levels
+	return f
+}
+
+// Init init the writer
+func (f *FilterWriter) Init() error {
+	return f.writer.Init()
+}
+
+// Write write the record to the writer if the predicate accepts it
+func (f *FilterWriter) Write(r *Record) error {
+	if !f.predicate(r) {
+		return nil
+	}
+	return f.writer.Write(r)
+}
+
+// Flush flush the writer
+func (f *FilterWriter) Flush() error {
+	return flushChildren([]Writer{f.writer})
+}
+
+// Rotate rotate the writer
+func (f *FilterWriter) Rotate() error {
+	return rotateChildren([]Writer{f.writer})
+}
+
+// SetPathPattern set path pattern of the writer if it is a Rotater
+func (f *FilterWriter) SetPathPattern(pattern string) error {
+	if r, ok := f.writer.(Rotater); ok {
+		return r.SetPathPattern(pattern)
+	}
+	return errCompositePathPattern
+}
+
+// Close close the writer
+func (f *FilterWriter) Close() error {
+	return closeChildren([]Writer{f.writer})
+}
+
+// Levels levels of the writer, narrowed by LevelFilter
+func (f *FilterWriter) Levels() LevelSet {
+	return f.levels
+}
+
+// FailoverWriter writes records to the active child, switches to the next child when it fails,
+// and switches back to a previous child after it passes the health check
+type FailoverWriter struct {
+	writers []Writer
+
+	lock          sync.Mutex
+	active        int
+	inited        []bool    // children inited, others are inited again by health checks
+	checkAt       time.Time // time of next health check
+	checkInterval time.Duration
+	clock         Clock
+}
+
+// Failover create failover writer, records are written to the primary until it fails
+func Failover(primary Writer, secondary ...Writer) *FailoverWriter {
+	return &FailoverWriter{
+		writers:       append([]Writer{primary}, secondary...),
+		checkInterval: failoverCheckIntervalDefault,
+	}
+}
+
+// SetCheckInterval set the interval of health checks of previous children, 30s by default
+func (f *FailoverWriter) SetCheckInterval(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.checkInterval = d
+}
+
+// SetClock set the clock of health checks, should call before Init
+func (f *FailoverWriter) SetClock(c Clock) {
+	f.clock = c
+}
+
+// Active index of the active child, 0 is the primary
+func (f *FailoverWriter) Active() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.active
+}
+
+// Init init all children, start on the first child inited, fail only if no child is inited.
+// Children failed to init are checked like failed children
+func (f *FailoverWriter) Init() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var errs []error
+	f.active = -1
+	f.inited = make([]bool, len(f.writers))
+	for i, w := range f.writers {
+		if err := w.Init(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		f.inited[i] = true
+		if f.active < 0 {
+			f.active = i
+		}
+	}
+	if f.active < 0 {
+		f.active = 0
+		return joinErrors(errs)
+	}
+	f.checkAt = clockOrSystem(f.clock).Now().Add(f.checkInterval)
+	return nil
+}
+
+// Write write the record to the active child, or the next children if it fails.
+// Previous children are checked every check interval: by Health if it's a HealthChecker,
+// or by writing the record, the first healthy child becomes active
+func (f *FailoverWriter) Write(r *Record) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	now := clockOrSystem(f.clock).Now()
+	if f.active > 0 && !now.Before(f.checkAt) {
+		f.checkAt = now.Add(f.checkInterval)
+		for i := 0; i < f.active; i++ {
+			w := f.writers[i]
+			if f.initChild(i) != nil {
+				continue
+			}
+			if h, ok := w.(HealthChecker); ok {
+				if h.Health() == nil {
+					f.active = i
+					break
+				}
+				continue
+			}
+			if w.Write(r) == nil {
+				f.active = i
+				return nil
+			}
+		}
+	}
+
+	var errs []error
+	for i := f.active; i < len(f.writers); i++ {
+		if err := f.initChild(i); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := f.writers[i].Write(r); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if i != f.active {
+			f.active = i
+			f.checkAt = now.Add(f.checkInterval)
+		}
+		return nil
+	}
+	return joinErrors(errs)
+}
+
+// initChild init the child failed to init by Init again
+func (f *FailoverWriter) initChild(i int) error {
+	if f.inited == nil || f.inited[i] {
+		return nil
+	}
+	if err := f.writers[i].Init(); err != nil {
+		return err
+	}
+	f.inited[i] = true
+	return nil
+}
+
+// Flush flush all children, errors of inactive children are ignored
+func (f *FailoverWriter) Flush() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var activeErr error
+	for i, w := range f.writers {
+		if err := flushChildren([]Writer{w}); err != nil && i == f.active {
+			activeErr = err
+		}
+	}
+	return activeErr
+}
+
+// Rotate rotate all children, errors of inactive children are ignored
+func (f *FailoverWriter) Rotate() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var activeErr error
+	for i, w := range f.writers {
+		if err := rotateChildren([]Writer{w}); err != nil && i == f.active {
+			activeErr = err
+		}
+	}
+	return activeErr
+}
+
+// SetPathPattern not supported, set it on children
+func (f *FailoverWriter) SetPathPattern(string) error {
+	return errCompositePathPattern
+}
+
+// Close close all children
+func (f *FailoverWriter) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return closeChildren(f.writers)
+}
+
+// Levels union of levels of children
+func (f *FailoverWriter) Levels() LevelSet {
+	return levelsOfChildren(f.writers)
+}
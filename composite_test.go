@@ -0,0 +1,254 @@
+package golog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// childWriter records messages and calls of composite writers
+type childWriter struct {
+	levels  LevelSet
+	fail    error
+	healthy error
+	lines   []string
+	calls   []string
+}
+
+func (w *childWriter) Init() error {
+	w.calls = append(w.calls, "init")
+	return nil
+}
+
+func (w *childWriter) Write(r *Record) error {
+	if w.fail != nil {
+		return w.fail
+	}
+	if w.levels != 0 && !w.levels.Contains(r.level) {
+		return nil
+	}
+	w.lines = append(w.lines, r.msg)
+	return nil
+}
+
+func (w *childWriter) Flush() error {
+	w.calls = append(w.calls, "flush")
+	return w.fail
+}
+
+func (w *childWriter) Rotate() error {
+	w.calls = append(w.calls, "rotate")
+	return w.fail
+}
+
+func (w *childWriter) SetPathPattern(string) error {
+	return nil
+}
+
+func (w *childWriter) Close() error {
+	w.calls = append(w.calls, "close")
+	return nil
+}
+
+func (w *childWriter) Levels() LevelSet {
+	if w.levels == 0 {
+		return LevelSetAll
+	}
+	return w.levels
+}
+
+func (w *childWriter) Health() error {
+	return w.healthy
+}
+
+func Test_Tee(t *testing.T) {
+	errs := &childWriter{levels: NewLevelSet(ERROR)}
+	all := &childWriter{}
+	access := &childWriter{}
+	tee := Tee(errs, all, LevelFilter(access, NewLevelSet(ACCESS)))
+	if err := tee.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*Record{{level: ERROR, msg: "e"}, {level: ACCESS, msg: "a"}, {level: DEBUG, msg: "d"}} {
+		if err := tee.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tee.Flush()
+	tee.Rotate()
+	tee.Close()
+
+	if !reflect.DeepEqual(errs.lines, []string{"e"}) || !reflect.DeepEqual(all.lines, []string{"e", "a", "d"}) ||
+		!reflect.DeepEqual(access.lines, []string{"a"}) {
+		t.Errorf("lines = %v, %v, %v", errs.lines, all.lines, access.lines)
+	}
+	for _, w := range []*childWriter{errs, all, access} {
+		if want := []string{"init", "flush", "rotate", "close"}; !reflect.DeepEqual(w.calls, want) {
+			t.Errorf("calls = %v, want %v", w.calls, want)
+		}
+	}
+	if got := Tee(errs, LevelFilter(access, NewLevelSet(ACCESS))).Levels(); got != NewLevelSet(ERROR, ACCESS) {
+		t.Errorf("Levels() = %v", got)
+	}
+	if err := tee.SetPathPattern("x.log"); err == nil {
+		t.Error("SetPathPattern() want error")
+	}
+}
+
+func Test_TeeErrors(t *testing.T) {
+	ok := &childWriter{}
+	tee := Tee(&childWriter{fail: errors.New("a")}, ok, &childWriter{fail: errors.New("b")})
+	err := tee.Write(&Record{level: COMMON, msg: "m"})
+	if err == nil || err.Error() != "a; b" {
+		t.Errorf("Write() err = %v", err)
+	}
+	if !reflect.DeepEqual(ok.lines, []string{"m"}) {
+		t.Errorf("lines = %v", ok.lines)
+	}
+}
+
+func Test_Filter(t *testing.T) {
+	w := &childWriter{levels: NewLevelSet(ERROR, COMMON)}
+	f := Filter(w, func(r *Record) bool { return !strings.Contains(r.msg, "password") })
+	f.Write(&Record{level: ERROR, msg: "password=1"})
+	f.Write(&Record{level: ERROR, msg: "login failed"})
+	if !reflect.DeepEqual(w.lines, []string{"login failed"}) {
+		t.Errorf("lines = %v", w.lines)
+	}
+	if f.Levels() != w.levels {
+		t.Errorf("Levels() = %v", f.Levels())
+	}
+	if got := LevelFilter(w, NewLevelSet(COMMON, DEBUG)).Levels(); got != NewLevelSet(COMMON) {
+		t.Errorf("LevelFilter Levels() = %v", got)
+	}
+}
+
+func Test_Failover(t *testing.T) {
+	clock := &stepClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	primary := &childWriter{}
+	secondary := &childWriter{}
+	f := Failover(primary, secondary)
+	f.SetClock(clock)
+	f.SetCheckInterval(time.Minute)
+
+	f.Write(&Record{msg: "1"})
+	primary.fail = errors.New("disk full")
+	f.Write(&Record{msg: "2"})
+	if f.Active() != 1 {
+		t.Fatalf("Active() = %d, want 1", f.Active())
+	}
+	primary.fail = nil
+	f.Write(&Record{msg: "3"}) // not checked yet
+	clock.now = clock.now.Add(time.Minute)
+	f.Write(&Record{msg: "4"}) // written to the primary by the check
+	f.Write(&Record{msg: "5"})
+
+	if !reflect.DeepEqual(primary.lines, []string{"1", "4", "5"}) || !reflect.DeepEqual(secondary.lines, []string{"2", "3"}) {
+		t.Errorf("lines = %v, %v", primary.lines, secondary.lines)
+	}
+	if f.Active() != 0 {
+		t.Errorf("Active() = %d, want 0", f.Active())
+	}
+
+	secondary.fail = errors.New("down")
+	primary.fail = errors.New("disk full")
+	if err := f.Write(&Record{msg: "6"}); err == nil || err.Error() != "disk full; down" {
+		t.Errorf("Write() err = %v", err)
+	}
+	if err := f.Flush(); err == nil {
+		t.Error("Flush() want error of the active writer")
+	}
+}
+
+func Test_FailoverRotate(t *testing.T) {
+	primary := &childWriter{}
+	standby := &childWriter{fail: errors.New("down")}
+	f := Failover(primary, standby)
+	if err := f.Rotate(); err != nil {
+		t.Errorf("Rotate() err = %v, want errors of the standby ignored", err)
+	}
+	if !reflect.DeepEqual(standby.calls, []string{"rotate"}) {
+		t.Errorf("standby calls = %v", standby.calls)
+	}
+	primary.fail = errors.New("disk full")
+	if err := f.Rotate(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Rotate() err = %v, want error of the active writer", err)
+	}
+}
+
+func Test_FailoverHealthCheck(t *testing.T) {
+	clock := &stepClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	primary := &childWriter{fail: errors.New("down")}
+	secondary := &childWriter{}
+	f := Failover(primary, secondary)
+	f.SetClock(clock)
+
+	f.Write(&Record{msg: "1"})
+	clock.now = clock.now.Add(failoverCheckIntervalDefault)
+	primary.healthy = errors.New("still down")
+	f.Write(&Record{msg: "2"})
+	clock.now = clock.now.Add(failoverCheckIntervalDefault)
+	primary.healthy = nil
+	primary.fail = nil
+	f.Write(&Record{msg: "3"})
+
+	if !reflect.DeepEqual(primary.lines, []string{"3"}) || !reflect.DeepEqual(secondary.lines, []string{"1", "2"}) {
+		t.Errorf("lines = %v, %v", primary.lines, secondary.lines)
+	}
+}
+
+func Test_CompositeWithLogger(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	primary := &memoryWriter{}
+	l.Register(Tee(LevelFilter(primary, NewLevelSet(ERROR)), Filter(&memoryWriter{}, func(*Record) bool { return false })))
+	l.Error("e")
+	l.Common("c")
+	l.Sync()
+	primary.lock.Lock()
+	defer primary.lock.Unlock()
+	if !reflect.DeepEqual(primary.lines, []string{"ERROR e"}) {
+		t.Errorf("lines = %v", primary.lines)
+	}
+}
+
+// initFailingWriter fails to init until fixed
+type initFailingWriter struct {
+	childWriter
+	initErr error
+}
+
+func (w *initFailingWriter) Init() error {
+	return w.initErr
+}
+
+func Test_FailoverInit(t *testing.T) {
+	clock := &stepClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	primary := &initFailingWriter{initErr: errors.New("no disk")}
+	secondary := &childWriter{}
+	f := Failover(primary, secondary)
+	f.SetClock(clock)
+	if err := f.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if f.Active() != 1 {
+		t.Fatalf("Active() = %d, want 1", f.Active())
+	}
+	f.Write(&Record{msg: "1"})
+	clock.now = clock.now.Add(failoverCheckIntervalDefault)
+	f.Write(&Record{msg: "2"}) // init fails again
+	primary.initErr = nil
+	clock.now = clock.now.Add(failoverCheckIntervalDefault)
+	f.Write(&Record{msg: "3"})
+
+	if !reflect.DeepEqual(primary.lines, []string{"3"}) || !reflect.DeepEqual(secondary.lines, []string{"1", "2"}) {
+		t.Errorf("lines = %v, %v", primary.lines, secondary.lines)
+	}
+
+	all := Failover(&initFailingWriter{initErr: errors.New("a")}, &initFailingWriter{initErr: errors.New("b")})
+	if err := all.Init(); err == nil || err.Error() != "a; b" {
+		t.Errorf("Init() err = %v", err)
+	}
+}
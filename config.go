@@ -2,6 +2,7 @@ package golog
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
@@ -13,6 +14,8 @@ var GlobalLevel = DEBUG
 const (
 	WriterNameConsole = "console_writer"
 	WriterNameFile    = "file_writer"
+	WriterNameFiles   = "file_writers"
+	WriterNameWriters = "writers"
 )
 
 // LogConfig log config
@@ -21,93 +24,257 @@ type LogConfig struct {
 	Level string `json:"level" mapstructure:"level"`
 	Debug bool   `json:"debug" mapstructure:"debug"` // output log info or not for go-log
 	//If display full path of the file which log belongs to
-	FullPath      bool                 `json:"full_path" mapstructure:"full_path"`
+	FullPath bool `json:"full_path" mapstructure:"full_path"`
+	// Location of record time and file writers, like "UTC", "Local" or "Asia/Shanghai"
+	Location      string               `json:"location" mapstructure:"location"`
 	ConsoleWriter ConsoleWriterOptions `json:"console_writer" mapstructure:"console_writer"`
 	FileWriter    FileWriterOptions    `json:"file_writer" mapstructure:"file_writer"`
+	// FileWriters multiple named file writers, route records to files by levels
+	FileWriters []FileWriterOptions `json:"file_writers" mapstructure:"file_writers"`
+	// Writers writers of any registered type, created by the writer factories
+	Writers []WriterConfig `json:"writers" mapstructure:"writers"`
+	// Synchronous write records by the caller instead of the logger goroutine, for CLIs and short-lived jobs
+	Synchronous bool `json:"synchronous" mapstructure:"synchronous"`
+	// FlushLevel writers are flushed after records with level up to it in synchronous mode, debug if empty
+	FlushLevel string `json:"flush_level" mapstructure:"flush_level"`
 }
 
-// SetupLog setup log
+// SetupLog setup log, writers created by the former setup are replaced,
+// return ConfigErrors if the config is invalid or writers fail to init
 func SetupLog(lc LogConfig) (err error) {
 	if !lc.Debug {
 		log.SetOutput(ioutil.Discard)
 		defer log.SetOutput(os.Stdout)
 	}
 
+	return setupLogger(loggerDefault, lc)
+}
+
+// setupLogger setup the logger with config, split from SetupLog for go test,
+// logs of go-log are printed by the log package if Debug, the log output is not changed
+func setupLogger(l *Logger, lc LogConfig) (err error) {
+	if err = lc.Validate(); err != nil {
+		return err
+	}
+
 	// global config
 	GlobalLevel = getLevel(lc.Level)
 
 	// writer enable
 	// 1. if not set level, use global level;
 	// 2. if set level, use min level
+	// 3. if set levels, use the max level of the set
 	validGlobalMinLevel := ACCESS // default max level
 	validGlobalMinLevelBy := "global"
 
-	fileWriterLevelDefault := GlobalLevel
-	consoleWriterLevelDefault := GlobalLevel
+	writers := make([]configuredWriter, 0, 2+len(lc.FileWriters)+len(lc.Writers))
+	enable := func(w Writer, field, name string, levels LevelSet, options interface{}) {
+		validGlobalMinLevel = maxInt(levels.Max(), validGlobalMinLevel)
+		if validGlobalMinLevel == levels.Max() {
+			validGlobalMinLevelBy = name
+		}
+		debugf(lc.Debug, "[go-log] enable %s with levels %s", name, levels)
+		writers = append(writers, configuredWriter{field: field, name: name, key: configuredWriterKey(name, options), w: w})
+	}
 
 	if lc.ConsoleWriter.Enable {
-		consoleWriterLevelDefault = getLevelDefault(lc.ConsoleWriter.Level, GlobalLevel, WriterNameConsole)
-		validGlobalMinLevel = maxInt(consoleWriterLevelDefault, validGlobalMinLevel)
-		if validGlobalMinLevel == consoleWriterLevelDefault {
-			validGlobalMinLevelBy = WriterNameConsole
+		w := newConsoleWriter(lc.ConsoleWriter, GlobalLevel, WriterNameConsole)
+		enable(w, WriterNameConsole, WriterNameConsole, w.levels, lc.ConsoleWriter)
+	}
+
+	// file writers use the global location by default
+	if lc.FileWriter.Location == "" {
+		lc.FileWriter.Location = lc.Location
+	}
+	for i := range lc.FileWriters {
+		if lc.FileWriters[i].Location == "" {
+			lc.FileWriters[i].Location = lc.Location
 		}
 	}
 
 	if lc.FileWriter.Enable {
-		fileWriterLevelDefault = getLevelDefault(lc.FileWriter.Level, GlobalLevel, WriterNameFile)
-		validGlobalMinLevel = maxInt(fileWriterLevelDefault, validGlobalMinLevel)
-		if validGlobalMinLevel == fileWriterLevelDefault {
-			validGlobalMinLevelBy = WriterNameFile
-		}
+		w := newFileWriter(lc.FileWriter, GlobalLevel, WriterNameFile)
+		enable(w, WriterNameFile, WriterNameFile, w.levels, lc.FileWriter)
 	}
 
-	fullPath := lc.FullPath
-	WithFullPath(fullPath)
-	SetLevel(validGlobalMinLevel)
+	// entries of file writers are always enabled
+	for i, options := range lc.FileWriters {
+		field := fmt.Sprintf("%s[%d]", WriterNameFiles, i)
+		name := options.Name
+		if name == "" {
+			name = field
+		}
+		w := newFileWriter(options, GlobalLevel, name)
+		enable(w, field, name, w.levels, options)
+	}
 
-	if lc.ConsoleWriter.Enable {
-		w := NewConsoleWriterWithOptions(lc.ConsoleWriter)
-		w.level = consoleWriterLevelDefault
-		log.Printf("[go-log] enable " + WriterNameConsole + " with level " + LevelFlags[consoleWriterLevelDefault])
-		Register(w)
+	for i, wc := range lc.Writers {
+		field := fmt.Sprintf("%s[%d]", WriterNameWriters, i)
+		name := wc.Name
+		if name == "" {
+			name = field
+		}
+		w, err := NewWriter(wc.Type, wc.Options)
+		if err != nil {
+			return ConfigErrors{{Field: field + ".options", Err: err}}
+		}
+		levels := LevelSetAll
+		if lw, ok := w.(Leveler); ok {
+			levels = lw.Levels()
+		}
+		enable(w, field, name, levels, wc)
 	}
 
-	if lc.FileWriter.Enable {
-		w := NewFileWriterWithOptions(lc.FileWriter)
-		w.level = fileWriterLevelDefault
-		log.Printf("[go-log] enable    " + WriterNameFile + " with level " + LevelFlags[fileWriterLevelDefault])
-		Register(w)
+	if err = l.applyWriters(writers, lc.Debug); err != nil {
+		return err
 	}
+	loc, _ := LoadLocation(lc.Location) // validated
+	l.SetLocation(loc)
+	l.WithFullPath(lc.FullPath)
+	l.SetSynchronous(lc.Synchronous, LevelsUpTo(getLevel(lc.FlushLevel)))
+	l.SetLevel(validGlobalMinLevel)
 
-	log.Printf("[go-log] valid global_level(min:%v, flag:%v, by:%v), default(%v, flag:%v)",
+	debugf(lc.Debug, "[go-log] valid global_level(min:%v, flag:%v, by:%v), default(%v, flag:%v)",
 		validGlobalMinLevel, LevelFlags[validGlobalMinLevel], validGlobalMinLevelBy, GlobalLevel, LevelFlags[GlobalLevel])
 	return nil
 }
 
-// SetLogWithConf setup log with config file
-func SetLogWithConf(file string) (err error) {
-	var lc LogConfig
-	cnt, err := ioutil.ReadFile(file)
+// configuredWriter writer created by config
+type configuredWriter struct {
+	field string // config path of the writer
+	name  string
+	key   string // writer with the same key is kept when config is applied again
+	w     Writer
+}
+
+// configuredWriterKey key of writer by name, options and the global level used as default level
+func configuredWriterKey(name string, options interface{}) string {
+	cnt, _ := json.Marshal(options)
+	return fmt.Sprintf("%s|%d|%s", name, GlobalLevel, cnt)
+}
+
+// applyWriters replace the writers created by the former config with writers,
+// writers with unchanged config are kept, new writers are initialized,
+// removed writers are flushed and closed after no record is written to them.
+// Applies are serialized by configLock, removed writers are logged if debug
+func (l *Logger) applyWriters(writers []configuredWriter, debug bool) error {
+	l.configLock.Lock()
+	defer l.configLock.Unlock()
+	l.writersLock.RLock()
+	former := make(map[string]configuredWriter, len(l.configured))
+	for _, cw := range l.configured {
+		former[cw.key] = cw
+	}
+	l.writersLock.RUnlock()
+
+	inited := make([]configuredWriter, 0, len(writers))
+	for i, cw := range writers {
+		if f, ok := former[cw.key]; ok {
+			writers[i].w = f.w
+			delete(former, cw.key)
+			continue
+		}
+		if err := cw.w.Init(); err != nil {
+			for _, iw := range inited {
+				l.closeWriter(iw.w, iw.name)
+			}
+			return ConfigErrors{{Field: cw.field, Err: err}}
+		}
+		inited = append(inited, cw)
+	}
 
-	if err = json.Unmarshal(cnt, &lc); err != nil {
+	l.writersLock.Lock()
+	configured := make(map[Writer]bool, len(l.configured))
+	for _, cw := range l.configured {
+		configured[cw.w] = true
+	}
+	all := make([]Writer, 0, len(l.writers)+len(writers))
+	for _, w := range l.writers {
+		if !configured[w] {
+			all = append(all, w) // registered by hand
+		}
+	}
+	for _, cw := range writers {
+		all = append(all, cw.w)
+	}
+	l.writers = all
+	l.configured = writers
+	l.writersLock.Unlock()
+
+	for _, cw := range former {
+		debugf(debug, "[go-log] remove %s", cw.name)
+		l.closeWriter(cw.w, cw.name)
+		l.forgetWriter(cw.w)
+	}
+	return nil
+}
+
+// debugf print logs of go-log by the log package if debug
+func debugf(debug bool, format string, args ...interface{}) {
+	if debug {
+		log.Printf(format, args...)
+	}
+}
+
+// closeWriter flush and close writer, errors are reported by the name to the error handler
+func (l *Logger) closeWriter(w Writer, name string) {
+	if f, ok := w.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			l.namedWriterError(w, name, WriterOpFlush, err)
+		}
+	}
+	if c, ok := w.(Closer); ok {
+		if err := c.Close(); err != nil {
+			l.namedWriterError(w, name, WriterOpClose, err)
+		}
+	}
+}
+
+// SetLogWithConf setup log with config file,
+// json, yaml or toml by extension, overlaid by environment variables
+func SetLogWithConf(file string) (err error) {
+	lc, err := LoadConfig(file)
+	if err != nil {
 		return
 	}
 	return SetupLog(lc)
 }
 
-// SetLog setup log with config []byte
+// SetLog setup log with json config []byte, overlaid by environment variables
 func SetLog(config []byte) (err error) {
 	var lc LogConfig
 	if err = json.Unmarshal(config, &lc); err != nil {
 		return
 	}
+	if err = ApplyEnv(&lc); err != nil {
+		return
+	}
 	return SetupLog(lc)
 }
 
+// getLevel level of flag, DEBUG if not set
 func getLevel(flag string) int {
+	if flag == "" {
+		return DEBUG
+	}
 	return getLevelDefault(flag, DEBUG, "")
 }
 
+// writerLevels levels of writer, use levels if set, or up to level, or up to defaultLevel
+func writerLevels(level string, levels []string, defaultLevel int, writer string) LevelSet {
+	if len(levels) > 0 {
+		s, err := ParseLevelSet(levels)
+		if err == nil {
+			return s
+		}
+		log.Printf("[golog] invalid levels for writer(%v, levels:%v): %v, use level instead", writer, levels, err)
+	}
+	if level == "" {
+		return LevelsUpTo(defaultLevel)
+	}
+	return LevelsUpTo(getLevelDefault(level, defaultLevel, writer))
+}
+
 // maxInt return max int
 func maxInt(a, b int) int {
 	if a < b {
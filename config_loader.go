@@ -0,0 +1,186 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// config formats
+const (
+	ConfigFormatJSON = "json"
+	ConfigFormatYAML = "yaml"
+	ConfigFormatTOML = "toml"
+)
+
+// EnvPrefix prefix of the environment variables overlaid on config,
+// like GOLOG_LEVEL, GOLOG_FILE_WRITER_FILENAME, GOLOG_CONSOLE_WRITER_COLOR
+const EnvPrefix = "GOLOG"
+
+// LoadConfig load config from file, format detected by extension
+// (.yaml/.yml, .toml, json by default), and overlay environment variables
+func LoadConfig(file string) (lc LogConfig, err error) {
+	cnt, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	if lc, err = ParseConfig(cnt, configFormat(file)); err != nil {
+		return
+	}
+	err = ApplyEnv(&lc)
+	return
+}
+
+// ParseConfig parse config content in the format
+func ParseConfig(config []byte, format string) (lc LogConfig, err error) {
+	var v interface{}
+	switch format {
+	case ConfigFormatJSON:
+		err = json.Unmarshal(config, &lc)
+		return
+	case ConfigFormatYAML:
+		err = yaml.Unmarshal(config, &v)
+	case ConfigFormatTOML:
+		m := make(map[string]interface{})
+		err = toml.Unmarshal(config, &m)
+		v = m
+	default:
+		err = errors.New("unknown config format (" + format + ")")
+	}
+	if err != nil {
+		return
+	}
+
+	// decode by json tags, so all formats share the same keys
+	cnt, err := json.Marshal(jsonCompatible(v))
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(cnt, &lc)
+	return
+}
+
+// ApplyEnv overlay environment variables on config,
+// the variable name is EnvPrefix and the field path joined by "_" in upper case,
+// lists of strings are separated by ",", durations are like "1m30s".
+// Setting a field not supported, like file_writers and writers, is an error
+func ApplyEnv(lc *LogConfig) error {
+	return applyEnv(reflect.ValueOf(lc).Elem(), EnvPrefix)
+}
+
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("mapstructure")
+		if tag == "" {
+			continue
+		}
+		name := prefix + "_" + strings.ToUpper(tag)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := applyEnv(field, name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(env)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(env)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			field.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if field.Type() == reflect.TypeOf(time.Duration(0)) {
+				d, err := time.ParseDuration(env)
+				if err != nil {
+					return fmt.Errorf("%s: %v", name, err)
+				}
+				field.SetInt(int64(d))
+				continue
+			}
+			n, err := strconv.ParseInt(env, 10, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			field.SetInt(n)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n, err := strconv.ParseUint(env, 10, field.Type().Bits())
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			field.SetUint(n)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.String {
+				return fmt.Errorf("%s: unsupported by environment variables, set it in the config file", name)
+			}
+			items := strings.Split(env, ",")
+			for j := range items {
+				items[j] = strings.TrimSpace(items[j])
+			}
+			field.Set(reflect.ValueOf(items))
+		default:
+			return fmt.Errorf("%s: unsupported by environment variables, set it in the config file", name)
+		}
+	}
+	return nil
+}
+
+// configFormat detect config format by file extension
+func configFormat(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return ConfigFormatYAML
+	case ".toml":
+		return ConfigFormatTOML
+	default:
+		return ConfigFormatJSON
+	}
+}
+
+// jsonCompatible convert yaml maps with non-string keys to json compatible maps
+func jsonCompatible(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			m[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range x {
+			x[k] = jsonCompatible(val)
+		}
+		return x
+	case []interface{}:
+		for i, val := range x {
+			x[i] = jsonCompatible(val)
+		}
+		return x
+	case []map[string]interface{}:
+		s := make([]interface{}, len(x))
+		for i, val := range x {
+			s[i] = jsonCompatible(val)
+		}
+		return s
+	default:
+		return v
+	}
+}
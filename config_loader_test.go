@@ -0,0 +1,149 @@
+package golog
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	logConfigYAML = `
+level: common
+full_path: true
+file_writer:
+  enable: true
+  filename: ./test/golog-test-%Y%M%D.log
+  levels: [access, error-abnormal]
+console_writer:
+  enable: true
+  color: true
+writers:
+  - type: file
+    name: txn
+    options:
+      filename: ./test/txn.log
+      levels: [transaction]
+`
+	logConfigTOML = `
+level = "common"
+full_path = true
+
+[file_writer]
+enable = true
+filename = "./test/golog-test-%Y%M%D.log"
+levels = ["access", "error-abnormal"]
+
+[console_writer]
+enable = true
+color = true
+
+[[writers]]
+type = "file"
+name = "txn"
+[writers.options]
+filename = "./test/txn.log"
+levels = ["transaction"]
+`
+)
+
+func Test_ParseConfig(t *testing.T) {
+	want := LogConfig{
+		Level:    "common",
+		FullPath: true,
+		FileWriter: FileWriterOptions{
+			Enable:   true,
+			Filename: "./test/golog-test-%Y%M%D.log",
+			Levels:   []string{"access", "error-abnormal"},
+		},
+		ConsoleWriter: ConsoleWriterOptions{Enable: true, Color: true},
+	}
+	for format, config := range map[string]string{
+		ConfigFormatYAML: logConfigYAML,
+		ConfigFormatTOML: logConfigTOML,
+	} {
+		lc, err := ParseConfig([]byte(config), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(lc.Writers) != 1 || lc.Writers[0].Type != "file" || lc.Writers[0].Name != "txn" {
+			t.Errorf("%s: writers %#v", format, lc.Writers)
+			continue
+		}
+		w, err := NewWriter(lc.Writers[0].Type, lc.Writers[0].Options)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if fw := w.(*FileWriter); fw.filename != "./test/txn.log" || fw.levels != NewLevelSet(TRANSACTION) {
+			t.Errorf("%s: writer options filename %s levels %v", format, fw.filename, fw.levels)
+		}
+		lc.Writers = nil
+		if !reflect.DeepEqual(lc, want) {
+			t.Errorf("%s: got %#v, want %#v", format, lc, want)
+		}
+	}
+
+	if _, err := ParseConfig([]byte(logConfig), "ini"); err == nil {
+		t.Error("unknown format should fail")
+	}
+}
+
+func Test_LoadConfigWithEnv(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "golog.yml")
+	if err := ioutil.WriteFile(file, []byte(logConfigYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOLOG_LEVEL", "debug")
+	t.Setenv("GOLOG_FILE_WRITER_FILENAME", "/var/log/app.log")
+	t.Setenv("GOLOG_FILE_WRITER_LEVELS", "access, debug")
+	t.Setenv("GOLOG_CONSOLE_WRITER_COLOR", "false")
+	t.Setenv("GOLOG_FILE_WRITER_MAX_DAYS", "7")
+
+	lc, err := LoadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lc.Level != "debug" || lc.FileWriter.Filename != "/var/log/app.log" || lc.ConsoleWriter.Color ||
+		lc.FileWriter.MaxDays != 7 || !reflect.DeepEqual(lc.FileWriter.Levels, []string{"access", "debug"}) {
+		t.Errorf("env not overlaid: %#v", lc)
+	}
+	if !lc.FullPath || !lc.ConsoleWriter.Enable {
+		t.Errorf("file config lost: %#v", lc)
+	}
+
+	t.Setenv("GOLOG_CONSOLE_WRITER_COLOR", "maybe")
+	if _, err = LoadConfig(file); err == nil {
+		t.Error("invalid bool env should fail")
+	}
+	t.Setenv("GOLOG_CONSOLE_WRITER_COLOR", "false")
+
+	t.Setenv("GOLOG_FILE_WRITERS", "./logs/app.log")
+	if _, err = LoadConfig(file); err == nil || !strings.Contains(err.Error(), "GOLOG_FILE_WRITERS") {
+		t.Errorf("unsupported env err = %v", err)
+	}
+}
+
+func Test_applyEnvKinds(t *testing.T) {
+	var v struct {
+		Timeout time.Duration     `mapstructure:"timeout"`
+		Size    int64             `mapstructure:"size"`
+		Workers uint              `mapstructure:"workers"`
+		Tags    map[string]string `mapstructure:"tags"`
+	}
+	t.Setenv("TEST_TIMEOUT", "1m30s")
+	t.Setenv("TEST_SIZE", "1099511627776")
+	t.Setenv("TEST_WORKERS", "4")
+	if err := applyEnv(reflect.ValueOf(&v).Elem(), "TEST"); err != nil {
+		t.Fatal(err)
+	}
+	if v.Timeout != 90*time.Second || v.Size != 1<<40 || v.Workers != 4 {
+		t.Errorf("env not applied: %+v", v)
+	}
+
+	t.Setenv("TEST_TAGS", "a=b")
+	if err := applyEnv(reflect.ValueOf(&v).Elem(), "TEST"); err == nil {
+		t.Error("unsupported kind should fail")
+	}
+}
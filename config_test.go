@@ -1,6 +1,10 @@
 package golog
 
 import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"strings"
 	"testing"
 	"time"
 )
@@ -41,3 +45,112 @@ func TestConfig(t *testing.T) {
 
 	time.Sleep(1 * time.Second)
 }
+
+func Test_LevelFlags(t *testing.T) {
+	levels := map[string]int{
+		LevelFlagAccess:      ACCESS,
+		LevelFlagError:       ERROR,
+		LevelFlagTransaction: TRANSACTION,
+		LevelFlagAbnormal:    ABNORMAL,
+		LevelFlagCommon:      COMMON,
+		LevelFlagDebug:       DEBUG,
+	}
+	for flag, level := range levels {
+		if LevelFlags[level] != flag {
+			t.Errorf("LevelFlags[%d] = %s, want %s", level, LevelFlags[level], flag)
+		}
+		if got := getLevelDefault(flag, DEBUG, "test"); got != level {
+			t.Errorf("getLevelDefault(%s) = %d, want %d", flag, got, level)
+		}
+	}
+}
+
+func TestConfigFileWritersRoute(t *testing.T) {
+	dir := t.TempDir()
+	lc := LogConfig{
+		Level: "debug",
+		FileWriters: []FileWriterOptions{
+			{Name: "access", Filename: dir + "/access.log", Levels: []string{"access"}},
+			{Name: "txn", Filename: dir + "/txn.log", Levels: []string{"transaction"}},
+			{Name: "app", Filename: dir + "/app.log", Levels: []string{"error", "abnormal-debug"}},
+		},
+	}
+	records := make(chan *Record, uint(128))
+	lg := newLoggerWithRecords(records)
+	if err := setupLogger(lg, lc); err != nil {
+		t.Fatal(err)
+	}
+	lg.Access("route access")
+	lg.Transaction("route transaction")
+	lg.Error("route error")
+	lg.Debug("route debug")
+	lg.Close()
+
+	want := map[string][]string{
+		"access.log": {"[ACCESS] ", "route access"},
+		"txn.log":    {"[TRANSACTION] ", "route transaction"},
+		"app.log":    {"[ERROR] ", "route error", "[DEBUG] ", "route debug"},
+	}
+	for name, subs := range want {
+		cnt, err := ioutil.ReadFile(dir + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sub := range subs {
+			if !strings.Contains(string(cnt), sub) {
+				t.Errorf("%s should contain %q, got %q", name, sub, cnt)
+			}
+		}
+		if lines := strings.Count(string(cnt), "\n"); lines != len(subs)/2 {
+			t.Errorf("%s got %d lines, want %d", name, lines, len(subs)/2)
+		}
+	}
+}
+
+func Test_writerLevels(t *testing.T) {
+	cases := []struct {
+		level  string
+		levels []string
+		want   LevelSet
+	}{
+		{"", nil, LevelsUpTo(COMMON)},
+		{"error", nil, LevelsUpTo(ERROR)},
+		{"unknown", nil, LevelsUpTo(COMMON)},
+		{"error", []string{"access", "debug"}, NewLevelSet(ACCESS, DEBUG)},
+		{"error", []string{"unknown"}, LevelsUpTo(ERROR)},
+	}
+	for _, c := range cases {
+		if got := writerLevels(c.level, c.levels, COMMON, "test"); got != c.want {
+			t.Errorf("writerLevels(%q, %v) = %v, want %v", c.level, c.levels, got, c.want)
+		}
+	}
+
+	if w := NewFileWriterWithOptions(FileWriterOptions{Level: "abnormal"}); w.levels != LevelsUpTo(ABNORMAL) {
+		t.Errorf("file writer levels = %v", w.levels)
+	}
+	if w := NewConsoleWriterWithOptions(ConsoleWriterOptions{}); w.levels != LevelsUpTo(DEBUG) {
+		t.Errorf("console writer levels = %v", w.levels)
+	}
+}
+
+func Test_setupLoggerLogOutput(t *testing.T) {
+	var buf bytes.Buffer
+	out := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(out)
+
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	if err := setupLogger(l, LogConfig{ConsoleWriter: ConsoleWriterOptions{Enable: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if log.Writer() != &buf || buf.Len() != 0 {
+		t.Errorf("log output changed or written: %q", buf.String())
+	}
+	if err := setupLogger(l, LogConfig{Debug: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "[go-log] remove console_writer") {
+		t.Errorf("debug logs = %q", buf.String())
+	}
+}
@@ -0,0 +1,199 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ConfigError error of a config field, the field is the path like "file_writer.filename"
+type ConfigError struct {
+	Field string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap return the underlying error
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors aggregated config errors
+type ConfigErrors []*ConfigError
+
+func (es ConfigErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add error of the field, errors of nested fields are added with the field as prefix
+func (es *ConfigErrors) add(field string, err error) {
+	if err == nil {
+		return
+	}
+	var nested ConfigErrors
+	if errors.As(err, &nested) {
+		for _, e := range nested {
+			*es = append(*es, &ConfigError{Field: field + "." + e.Field, Err: e.Err})
+		}
+		return
+	}
+	*es = append(*es, &ConfigError{Field: field, Err: err})
+}
+
+// err return nil if no error, avoid the typed nil error
+func (es ConfigErrors) err() error {
+	if len(es) == 0 {
+		return nil
+	}
+	return es
+}
+
+// Validate validate the config, return ConfigErrors with all invalid fields
+func (lc *LogConfig) Validate() error {
+	var es ConfigErrors
+	if lc.Level != "" {
+		_, err := parseLevel(lc.Level)
+		es.add("level", err)
+	}
+	if lc.FlushLevel != "" {
+		_, err := parseLevel(lc.FlushLevel)
+		es.add("flush_level", err)
+	}
+	_, err := LoadLocation(lc.Location)
+	es.add("location", err)
+	if lc.ConsoleWriter.Enable {
+		es.add(WriterNameConsole, lc.ConsoleWriter.validate())
+	}
+	if lc.FileWriter.Enable {
+		es.add(WriterNameFile, lc.FileWriter.validate())
+	}
+
+	names := make(map[string]string)
+	uniqueName := func(field, name string) {
+		if name == "" {
+			return
+		}
+		if former, ok := names[name]; ok {
+			es.add(field+".name", fmt.Errorf("duplicate name %q of %s", name, former))
+			return
+		}
+		names[name] = field
+	}
+	for i, options := range lc.FileWriters {
+		field := fmt.Sprintf("%s[%d]", WriterNameFiles, i)
+		uniqueName(field, options.Name)
+		es.add(field, options.validate())
+	}
+	for i, wc := range lc.Writers {
+		field := fmt.Sprintf("%s[%d]", WriterNameWriters, i)
+		uniqueName(field, wc.Name)
+		if wc.Type == "" {
+			es.add(field+".type", errors.New("required"))
+			continue
+		}
+		// writers are not created by validation, they may hold resources
+		if !hasWriterFactory(wc.Type) {
+			es.add(field+".type", errors.New("no writer factory for type ("+wc.Type+")"))
+			continue
+		}
+		es.add(field+".options", validateWriterOptions(wc.Type, wc.Options))
+	}
+	return es.err()
+}
+
+// validateLevels validate level and levels of writer options
+func validateLevels(es *ConfigErrors, level string, levels []string) {
+	if level != "" {
+		_, err := parseLevel(level)
+		es.add("level", err)
+	}
+	for i, flag := range levels {
+		_, err := ParseLevelSet([]string{flag})
+		es.add(fmt.Sprintf("levels[%d]", i), err)
+	}
+}
+
+func (options *ConsoleWriterOptions) validate() error {
+	var es ConfigErrors
+	validateLevels(&es, options.Level, options.Levels)
+	return es.err()
+}
+
+func (options *FileWriterOptions) validate() error {
+	var es ConfigErrors
+	validateLevels(&es, options.Level, options.Levels)
+	if options.Filename == "" {
+		es.add("filename", errors.New("required"))
+	} else {
+		es.add("filename", NewFileWriter().SetPathPattern(options.Filename))
+	}
+	_, err := LoadLocation(options.Location)
+	es.add("location", err)
+	if options.Symlink != "" {
+		if _, err = parsePathPattern(options.Symlink); err != nil {
+			es.add("symlink", err)
+		} else if options.Symlink == options.Filename {
+			es.add("symlink", errors.New("same as filename"))
+		}
+	}
+	for _, f := range []struct {
+		field string
+		v     int
+	}{
+		{"max_days", options.MaxDays},
+		{"max_hours", options.MaxHours},
+		{"max_minutes", options.MaxMinutes},
+		{"max_seconds", options.MaxSeconds},
+		{"buffer_size", options.BufferSize},
+	} {
+		if f.v < 0 {
+			es.add(f.field, fmt.Errorf("negative value %d", f.v))
+		}
+	}
+	options.validateSync(&es)
+	if options.AuditKey != "" && !options.Audit {
+		es.add("audit_key", errors.New("requires audit"))
+	}
+	if len(options.EncryptKeyID) > 255 {
+		es.add("encrypt_key_id", errors.New("longer than 255"))
+	}
+	if options.Shared && options.Audit {
+		es.add("shared", errors.New("not supported with audit"))
+	}
+	if options.Shared && options.EncryptKeyID != "" {
+		es.add("shared", errors.New("not supported with encryption"))
+	}
+	return es.err()
+}
+
+// validateSync validate sync options of file writer
+func (options *FileWriterOptions) validateSync(es *ConfigErrors) {
+	mode, err := parseSyncMode(options.Sync)
+	if err != nil {
+		es.add("sync", err)
+		return
+	}
+	if len(options.SyncLevels) > 0 {
+		_, err = ParseLevelSet(options.SyncLevels)
+		es.add("sync_levels", err)
+	} else if mode == SyncLevel {
+		es.add("sync_levels", errors.New("required by sync mode level"))
+	}
+	if options.SyncInterval != "" {
+		d, err := time.ParseDuration(options.SyncInterval)
+		if err == nil && d <= 0 {
+			err = fmt.Errorf("non-positive interval %s", d)
+		}
+		es.add("sync_interval", err)
+	} else if mode == SyncInterval {
+		es.add("sync_interval", errors.New("required by sync mode interval"))
+	}
+}
@@ -0,0 +1,83 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func Test_LogConfigValidate(t *testing.T) {
+	lc := LogConfig{
+		Level:         "warn",
+		Location:      "Mars/Olympus_Mons",
+		FlushLevel:    "fast",
+		ConsoleWriter: ConsoleWriterOptions{Enable: true, Levels: []string{"access", "error-info"}},
+		FileWriter:    FileWriterOptions{Enable: true, Filename: "./test/golog-%Y%Q.log", MaxDays: -1, Symlink: "./test/%Q"},
+		FileWriters: []FileWriterOptions{
+			{Name: "app", Filename: "./test/app.log", Symlink: "./test/app.log"},
+			{Name: "app", Level: "debug"},
+		},
+		Writers: []WriterConfig{
+			{Type: "syslog"},
+			{Type: "file", Options: json.RawMessage(`{"filename": "./test/golog-%"}`)},
+			{Name: "console"},
+		},
+	}
+	err := lc.Validate()
+	var es ConfigErrors
+	if !errors.As(err, &es) {
+		t.Fatalf("validate err %v should be ConfigErrors", err)
+	}
+	want := []string{
+		"level: invalid level flag (warn)",
+		"flush_level: invalid level flag (fast)",
+		"location: unknown time zone Mars/Olympus_Mons",
+		"console_writer.levels[1]: invalid level flag (info)",
+		"file_writer.filename: invalid rotate pattern %Q in (./test/golog-%Y%Q.log)",
+		"file_writer.symlink: invalid rotate pattern %Q in (./test/%Q)",
+		"file_writer.max_days: negative value -1",
+		"file_writers[0].symlink: same as filename",
+		"file_writers[1].name: duplicate name \"app\" of file_writers[0]",
+		"file_writers[1].filename: required",
+		"writers[0].type: no writer factory for type (syslog)",
+		"writers[1].options.filename: invalid rotate pattern % at the end of (./test/golog-%)",
+		"writers[2].type: required",
+	}
+	if len(es) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(es), len(want), err)
+	}
+	for i, e := range es {
+		if e.Error() != want[i] {
+			t.Errorf("error %d got %q, want %q", i, e.Error(), want[i])
+		}
+	}
+
+	valid := LogConfig{Level: "common", FileWriter: FileWriterOptions{Filename: "%Q"}}
+	if err = valid.Validate(); err != nil {
+		t.Errorf("disabled writer should not be validated, got %v", err)
+	}
+}
+
+func Test_SetupLogWithInvalidConfig(t *testing.T) {
+	lg := newLoggerWithRecords(make(chan *Record, uint(1)))
+	defer lg.Close()
+
+	err := setupLogger(lg, LogConfig{Level: "verbose"})
+	if err == nil || err.Error() != "level: invalid level flag (verbose)" {
+		t.Errorf("setup with invalid level err %v", err)
+	}
+
+	dir := t.TempDir()
+	lc := LogConfig{FileWriters: []FileWriterOptions{
+		{Filename: dir + "/ok.log"},
+		{Filename: dir + "/ok.log/sub.log"},
+	}}
+	err = setupLogger(lg, lc)
+	var es ConfigErrors
+	if !errors.As(err, &es) || es[0].Field != "file_writers[1]" {
+		t.Errorf("setup with writer init failed err %v", err)
+	}
+	if len(lg.writers) != 0 {
+		t.Errorf("no writer should be registered, got %d", len(lg.writers))
+	}
+}
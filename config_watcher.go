@@ -0,0 +1,149 @@
+package golog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// ConfWatchInterval interval to check the config file for changes
+var ConfWatchInterval = time.Second * 5
+
+// ConfWatcher watch the config file and apply it on change
+type ConfWatcher struct {
+	file     string
+	interval time.Duration
+	apply    func(LogConfig) error
+
+	modTime time.Time
+	size    int64
+	sum     []byte
+
+	onReload     func(error) // called after each reload with the error, nil when applied
+	onReloadLock sync.Mutex
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// WatchConf watch the config file passed to SetLogWithConf,
+// on change the config is loaded, validated and applied to the default logger,
+// the output of the log package is not changed by the watcher
+func WatchConf(file string) (*ConfWatcher, error) {
+	return newConfWatcher(file, ConfWatchInterval, setupDefaultLogger, nil)
+}
+
+// WatchConfWithCallback watch the config file as WatchConf,
+// onReload is called after each reload with the error, nil when applied
+func WatchConfWithCallback(file string, onReload func(error)) (*ConfWatcher, error) {
+	return newConfWatcher(file, ConfWatchInterval, setupDefaultLogger, onReload)
+}
+
+// setupDefaultLogger setup the default logger without changing the output of the log package
+func setupDefaultLogger(lc LogConfig) error {
+	return setupLogger(loggerDefault, lc)
+}
+
+// newConfWatcher is useful for go test
+func newConfWatcher(file string, interval time.Duration, apply func(LogConfig) error, onReload func(error)) (*ConfWatcher, error) {
+	cw := &ConfWatcher{
+		file:     file,
+		interval: interval,
+		apply:    apply,
+		onReload: onReload,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	// the config in use, only changes after it are applied
+	if _, _, err := cw.changed(); err != nil {
+		return nil, err
+	}
+
+	go cw.watch()
+	return cw, nil
+}
+
+// SetOnReload set the func called after each reload with the error, nil when applied
+func (cw *ConfWatcher) SetOnReload(onReload func(error)) {
+	cw.onReloadLock.Lock()
+	defer cw.onReloadLock.Unlock()
+	cw.onReload = onReload
+}
+
+// Stop stop watching the config file
+func (cw *ConfWatcher) Stop() {
+	cw.stopOnce.Do(func() {
+		close(cw.stop)
+	})
+	<-cw.done
+}
+
+func (cw *ConfWatcher) watch() {
+	defer close(cw.done)
+	ticker := time.NewTicker(cw.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-cw.stop:
+			return
+		case <-ticker.C:
+			changed, cnt, err := cw.changed()
+			if err == nil && !changed {
+				continue
+			}
+			if err == nil {
+				err = cw.reload(cnt)
+			}
+			if err != nil {
+				log.Printf("[go-log] reload config %s err: %v", cw.file, err)
+			}
+			cw.onReloadLock.Lock()
+			onReload := cw.onReload
+			cw.onReloadLock.Unlock()
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}
+}
+
+// changed check the file by modify time and size first, then by the content
+func (cw *ConfWatcher) changed() (bool, []byte, error) {
+	fi, err := os.Stat(cw.file)
+	if err != nil {
+		return false, nil, err
+	}
+	if fi.ModTime().Equal(cw.modTime) && fi.Size() == cw.size {
+		return false, nil, nil
+	}
+
+	cnt, err := ioutil.ReadFile(cw.file)
+	if err != nil {
+		return false, nil, err
+	}
+	cw.modTime = fi.ModTime()
+	cw.size = fi.Size()
+	sum := sha256.Sum256(cnt)
+	if bytes.Equal(sum[:], cw.sum) {
+		return false, nil, nil
+	}
+	cw.sum = sum[:]
+	return true, cnt, nil
+}
+
+func (cw *ConfWatcher) reload(cnt []byte) error {
+	lc, err := ParseConfig(cnt, configFormat(cw.file))
+	if err != nil {
+		return err
+	}
+	if err = ApplyEnv(&lc); err != nil {
+		return err
+	}
+	return cw.apply(lc)
+}
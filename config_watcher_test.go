@@ -0,0 +1,101 @@
+package golog
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_WatchConf(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "golog.yml")
+	writeConf := func(filenames ...string) {
+		config := "level: debug\nfile_writers:\n"
+		for _, filename := range filenames {
+			config += "  - name: " + filename + "\n    filename: " + filepath.Join(dir, filename) + "\n"
+		}
+		if err := ioutil.WriteFile(file, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeConf("a.log")
+	lc, err := LoadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg := newLoggerWithRecords(make(chan *Record, uint(128)))
+	if err = setupLogger(lg, lc); err != nil {
+		t.Fatal(err)
+	}
+	aWriter := lg.writers[0].(*FileWriter)
+
+	reloaded := make(chan error, 1)
+	cw, err := newConfWatcher(file, time.Millisecond*10, func(lc LogConfig) error {
+		return setupLogger(lg, lc)
+	}, func(err error) {
+		reloaded <- err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cw.Stop()
+	waitReload := func() {
+		select {
+		case err := <-reloaded:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatal("config not reloaded")
+		}
+	}
+
+	lg.Common("before reload")
+	lg.Sync()
+	writeConf("a.log", "b.log")
+	waitReload()
+	if len(lg.writers) != 2 || lg.writers[0] != aWriter {
+		t.Fatalf("unchanged writer should be kept, got %#v", lg.writers)
+	}
+	lg.Common("after add")
+	lg.Sync()
+
+	writeConf("b.log")
+	waitReload()
+	if len(lg.writers) != 1 || len(aWriter.files) != 0 {
+		t.Fatalf("removed writer should be closed, got %#v", lg.writers)
+	}
+	lg.Common("after remove")
+	lg.Sync()
+
+	if err = ioutil.WriteFile(file, []byte("level: [debug"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = <-reloaded; err == nil {
+		t.Error("invalid config should fail to reload")
+	}
+	lg.Common("after invalid")
+	lg.Close()
+
+	want := map[string][]string{
+		"a.log": {"before reload", "after add"},
+		"b.log": {"after add", "after remove", "after invalid"},
+	}
+	for name, msgs := range want {
+		cnt, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range msgs {
+			if !strings.Contains(string(cnt), msg) {
+				t.Errorf("%s should contain %q, got %s", name, msg, cnt)
+			}
+		}
+	}
+	if cnt, _ := ioutil.ReadFile(filepath.Join(dir, "a.log")); strings.Contains(string(cnt), "after remove") {
+		t.Errorf("removed writer got record after remove: %s", cnt)
+	}
+}
@@ -74,7 +74,7 @@ func (r *colorRecord) String() string {
 
 // ConsoleWriter console writer define
 type ConsoleWriter struct {
-	level     int
+	levels    LevelSet
 	color     bool
 	fullColor bool // line all with color
 }
@@ -85,23 +85,24 @@ type ConsoleWriterOptions struct {
 	Color     bool   `json:"color" mapstructure:"color"`
 	FullColor bool   `json:"full_color" mapstructure:"full_color"`
 	Level     string `json:"level" mapstructure:"level"`
+	// Levels explicit levels or level ranges accepted, overrides Level if set
+	Levels []string `json:"levels" mapstructure:"levels"`
 }
 
 // NewConsoleWriter create new console writer
 func NewConsoleWriter() *ConsoleWriter {
-	return &ConsoleWriter{}
+	return &ConsoleWriter{levels: LevelSetAll}
 }
 
 // NewConsoleWriterWithOptions create new console writer with level
 func NewConsoleWriterWithOptions(options ConsoleWriterOptions) *ConsoleWriter {
-	defaultLevel := DEBUG
-
-	if len(options.Level) > 0 {
-		defaultLevel = getLevelDefault(options.Level, defaultLevel, "")
-	}
+	return newConsoleWriter(options, DEBUG, WriterTypeConsole)
+}
 
+// newConsoleWriter create console writer, up to defaultLevel if no level is set
+func newConsoleWriter(options ConsoleWriterOptions, defaultLevel int, name string) *ConsoleWriter {
 	return &ConsoleWriter{
-		level:     defaultLevel,
+		levels:    writerLevels(options.Level, options.Levels, defaultLevel, name),
 		color:     options.Color,
 		fullColor: options.FullColor,
 	}
@@ -109,7 +110,7 @@ func NewConsoleWriterWithOptions(options ConsoleWriterOptions) *ConsoleWriter {
 
 // Write console write
 func (w *ConsoleWriter) Write(r *Record) error {
-	if r.level > w.level {
+	if !w.levels.Contains(r.level) {
 		return nil
 	}
 	if w.color {
@@ -138,3 +139,18 @@ func (w *ConsoleWriter) SetColor(c bool) {
 func (w *ConsoleWriter) SetFullColor(c bool) {
 	w.fullColor = c
 }
+
+// SetLevel console output records with level up to lvl
+func (w *ConsoleWriter) SetLevel(lvl int) {
+	w.levels = LevelsUpTo(lvl)
+}
+
+// SetLevels console output records with level in the set only
+func (w *ConsoleWriter) SetLevels(levels LevelSet) {
+	w.levels = levels
+}
+
+// Levels levels accepted by console writer
+func (w *ConsoleWriter) Levels() LevelSet {
+	return w.levels
+}
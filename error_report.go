@@ -0,0 +1,203 @@
+package golog
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errorClassesMaxDefault default max count of error classes kept
+const errorClassesMaxDefault = 1000
+
+// patterns of variable parts stripped from format strings, in order
+var errorPatternReplacers = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), "<hex>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]*(?:[0-9][a-fA-F]|[a-fA-F][0-9])[0-9a-fA-F]*\b`), "<hex>"},
+	{regexp.MustCompile(`\b\d+(?:\.\d+)?`), "<n>"},
+}
+
+// formatVerb verbs of format string, kept as is by normalization
+var formatVerb = regexp.MustCompile(`%[-+# 0]*[0-9*]*(?:\.[0-9*]*)?[a-zA-Z%]`)
+
+// normalizeErrorPattern strip numbers, UUIDs and hex IDs from the format string, verbs are kept
+func normalizeErrorPattern(format string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range formatVerb.FindAllStringIndex(format, -1) {
+		b.WriteString(normalizeErrorText(format[last:loc[0]]))
+		b.WriteString(format[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(normalizeErrorText(format[last:]))
+	return b.String()
+}
+
+// normalizeErrorText strip numbers, UUIDs and hex IDs from text
+func normalizeErrorText(text string) string {
+	for _, r := range errorPatternReplacers {
+		text = r.re.ReplaceAllString(text, r.repl)
+	}
+	return text
+}
+
+// ErrorClass records of the same fingerprint, the caller and the normalized format string
+type ErrorClass struct {
+	Fingerprint string    `json:"fingerprint"`
+	Caller      string    `json:"caller"`
+	Pattern     string    `json:"pattern"` // format string with numbers and IDs stripped
+	Count       int64     `json:"count"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Sample      string    `json:"sample"` // message of the first record
+}
+
+// ErrorReportOptions error aggregator options
+type ErrorReportOptions struct {
+	// Levels of records aggregated, ERROR if empty
+	Levels LevelSet
+	// MaxClasses max count of classes kept, records of new classes are counted as dropped beyond it
+	MaxClasses int
+}
+
+// ErrorAggregator groups records by fingerprint in memory,
+// register it to logger by Logger.AddErrorAggregator, it's also a http.Handler of the report
+type ErrorAggregator struct {
+	options ErrorReportOptions
+
+	lock    sync.Mutex
+	classes map[string]*ErrorClass
+	dropped int64
+}
+
+// NewErrorAggregator create error aggregator
+func NewErrorAggregator(options ErrorReportOptions) *ErrorAggregator {
+	if options.Levels == 0 {
+		options.Levels = NewLevelSet(ERROR)
+	}
+	if options.MaxClasses <= 0 {
+		options.MaxClasses = errorClassesMaxDefault
+	}
+	return &ErrorAggregator{options: options, classes: make(map[string]*ErrorClass)}
+}
+
+// Levels levels aggregated
+func (a *ErrorAggregator) Levels() LevelSet {
+	return a.options.Levels
+}
+
+// fingerprintOf caller and normalized pattern of the record, the caller is the file and line
+func fingerprintOf(caller, pattern string) string {
+	sum := sha1.Sum([]byte(caller + "\n" + pattern))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Hook aggregate the record
+func (a *ErrorAggregator) Hook(r *Record) {
+	if !a.options.Levels.Contains(r.level) {
+		return
+	}
+	format := r.format
+	if format == "" {
+		format = r.msg
+	}
+	pattern := normalizeErrorPattern(format)
+	fp := fingerprintOf(r.file, pattern)
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	c, ok := a.classes[fp]
+	if !ok {
+		if len(a.classes) >= a.options.MaxClasses {
+			a.dropped++
+			return
+		}
+		c = &ErrorClass{
+			Fingerprint: fp,
+			Caller:      r.file,
+			Pattern:     pattern,
+			FirstSeen:   r.timestamp,
+			Sample:      r.msg,
+		}
+		a.classes[fp] = c
+	}
+	c.Count++
+	c.LastSeen = r.timestamp
+}
+
+// Top return copies of the n classes with the most records, all classes if n <= 0
+func (a *ErrorAggregator) Top(n int) []ErrorClass {
+	a.lock.Lock()
+	classes := make([]ErrorClass, 0, len(a.classes))
+	for _, c := range a.classes {
+		classes = append(classes, *c)
+	}
+	a.lock.Unlock()
+
+	sort.Slice(classes, func(i, j int) bool {
+		if classes[i].Count != classes[j].Count {
+			return classes[i].Count > classes[j].Count
+		}
+		return classes[i].LastSeen.After(classes[j].LastSeen)
+	})
+	if n > 0 && len(classes) > n {
+		classes = classes[:n]
+	}
+	return classes
+}
+
+// Dropped count of records of new classes dropped beyond MaxClasses
+func (a *ErrorAggregator) Dropped() int64 {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.dropped
+}
+
+// Reset drop all classes
+func (a *ErrorAggregator) Reset() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.classes = make(map[string]*ErrorClass)
+	a.dropped = 0
+}
+
+// errorReport JSON report of the error aggregator
+type errorReport struct {
+	Classes []ErrorClass `json:"classes"`
+	Dropped int64        `json:"dropped"`
+}
+
+// ServeHTTP serve the top classes as JSON, query "n" is the count of classes, 20 by default
+func (a *ErrorAggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := 20
+	if s := r.URL.Query().Get("n"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid n ("+s+")", http.StatusBadRequest)
+			return
+		}
+		n = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(errorReport{Classes: a.Top(n), Dropped: a.Dropped()})
+}
+
+// AddErrorAggregator register the aggregator as a hook of its levels
+func (l *Logger) AddErrorAggregator(a *ErrorAggregator) {
+	l.AddHook(a.Levels(), a.Hook)
+}
+
+// AddErrorAggregator register the aggregator to the default logger
+func AddErrorAggregator(a *ErrorAggregator) {
+	loggerDefault.AddErrorAggregator(a)
+}
@@ -0,0 +1,90 @@
+package golog_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+func Test_ErrorAggregator(t *testing.T) {
+	l, _ := gologtest.NewLogger(t)
+	clock := gologtest.NewFakeClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	l.SetClock(clock)
+	a := golog.NewErrorAggregator(golog.ErrorReportOptions{MaxClasses: 2})
+	l.AddErrorAggregator(a)
+
+	for i := 0; i < 3; i++ {
+		l.Error("order %d not found", i)
+		clock.Add(time.Second)
+	}
+	l.Error("user 42 timeout")
+	l.Error("user 43 timeout") // another caller line
+	l.Error("third class dropped")
+	l.Common("order %d not found", 9)
+	l.Sync()
+
+	top := a.Top(0)
+	if len(top) != 2 {
+		t.Fatalf("got %d classes, want 2", len(top))
+	}
+	c := top[0]
+	if c.Count != 3 || c.Pattern != "order %d not found" || c.Sample != "order 0 not found" {
+		t.Errorf("top class got %+v", c)
+	}
+	if !c.FirstSeen.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)) || !c.LastSeen.Equal(c.FirstSeen.Add(2*time.Second)) {
+		t.Errorf("top class seen got %v - %v", c.FirstSeen, c.LastSeen)
+	}
+	if top[1].Pattern != "user <n> timeout" || top[1].Count != 1 {
+		t.Errorf("second class got %+v", top[1])
+	}
+	if a.Dropped() != 2 {
+		t.Errorf("dropped got %d, want 2", a.Dropped())
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest("GET", "/errors?n=1", nil))
+	var report struct {
+		Classes []golog.ErrorClass `json:"classes"`
+		Dropped int64              `json:"dropped"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Classes) != 1 || report.Classes[0].Fingerprint != c.Fingerprint || report.Dropped != 2 {
+		t.Errorf("report got %+v", report)
+	}
+
+	w = httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest("GET", "/errors?n=x", nil))
+	if w.Code != 400 {
+		t.Errorf("status got %d, want 400", w.Code)
+	}
+}
+
+func Test_ErrorAggregatorPattern(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"order 12345 not found", "order <n> not found"},
+		{"retry %5.2f after 1.5s", "retry %5.2f after <n>s"},
+		{"request 123e4567-e89b-12d3-a456-426614174000 failed", "request <uuid> failed"},
+		{"object 5f2b9c1a deleted at 0x1f", "object <hex> deleted at <hex>"},
+		{"bad md5 of file", "bad md5 of file"},
+		{"100%% of %s", "<n>%% of %s"},
+	}
+	for _, tt := range tests {
+		l, _ := gologtest.NewLogger(t)
+		a := golog.NewErrorAggregator(golog.ErrorReportOptions{})
+		l.AddErrorAggregator(a)
+		l.Error(tt.format)
+		l.Sync()
+		if top := a.Top(1); len(top) != 1 || top[0].Pattern != tt.want {
+			t.Errorf("pattern of %q got %v, want %q", tt.format, top, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package golog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Field structured field of record, rendered as key=value after the message
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// String render the field as key=value, the value is quoted if it has spaces or special chars
+func (f Field) String() string {
+	return f.Key + "=" + fieldValue(f.Value)
+}
+
+// fieldValue format the value, quoted if it's empty or has spaces, quotes, = or control chars
+func fieldValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '"' || r == '=' || r == 0x7f
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// appendFields render fields after text
+func appendFields(b *strings.Builder, fields []Field) {
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(f.String())
+	}
+}
+
+// Fields structured fields of the record
+func (r *Record) Fields() []Field {
+	return r.fields
+}
+
+// Field value of the field key in record, ok is false if not found
+func (r *Record) Field(key string) (value interface{}, ok bool) {
+	for _, f := range r.fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// LogFields log the message with structured fields at level
+func (l *Logger) LogFields(level int, fields []Field, fmt string, args ...interface{}) {
+	l.deliverRecordWithFields(level, fields, fmt, args...)
+}
+
+// LogFields log the message with structured fields at level
+func LogFields(level int, fields []Field, fmt string, args ...interface{}) {
+	loggerDefault.deliverRecordWithFields(level, fields, fmt, args...)
+}
@@ -0,0 +1,272 @@
+package golog
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// marks of audit file lines
+const (
+	auditHeaderPrefix  = "# golog-audit v1 "
+	auditTrailerPrefix = "# golog-audit end "
+	auditChainSep      = " #chain="
+)
+
+// auditMaxRecordSize max size of a record in audit file, to bound the verification of a tampered file
+const auditMaxRecordSize = 1 << 20
+
+// ErrAuditNoTrailer audit segment without trailer, the file is being written,
+// the writer crashed, or lines at the end are deleted
+var ErrAuditNoTrailer = errors.New("audit segment without trailer")
+
+// AuditError error of audit file verification
+type AuditError struct {
+	Path string
+	Line int // line number from 1
+	Err  error
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("audit verify (%s) line %d: %v", e.Path, e.Line, e.Err)
+}
+
+func (e *AuditError) Unwrap() error {
+	return e.Err
+}
+
+// auditChain running chain of an audit segment, a segment is the lines from a header to its trailer,
+// every record line ends with the chain value, hash of the previous chain value and the record,
+// the first chain value is the hash of header, HMAC-SHA256 with key, SHA-256 otherwise
+type auditChain struct {
+	key   []byte
+	chain []byte
+	lines int
+}
+
+// newAuditChain start an audit segment with a random nonce, return the chain and header line,
+// the header is linked to the previous segment by its last chain value if prev is not empty
+func newAuditChain(key []byte, prev string) (*auditChain, string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", err
+	}
+	alg := "sha256"
+	if len(key) > 0 {
+		alg = "hmac-sha256"
+	}
+	header := auditHeaderPrefix + "alg=" + alg + " nonce=" + hex.EncodeToString(nonce)
+	if prev != "" {
+		header += " prev=" + prev
+	}
+	return startAuditChain(key, header), header + "\n", nil
+}
+
+// auditField value of the field name of header or trailer line, "" if not found
+func auditField(line, name string) string {
+	for _, f := range strings.Fields(line) {
+		if strings.HasPrefix(f, name+"=") {
+			return f[len(name)+1:]
+		}
+	}
+	return ""
+}
+
+// lastAuditChain last chain value of the audit file, from the trailer at the end,
+// "" if the file is empty or not closed by a trailer
+func lastAuditChain(file *os.File) string {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return ""
+	}
+	size := int64(4096)
+	if info.Size() < size {
+		size = info.Size()
+	}
+	data := make([]byte, size)
+	if _, err = file.ReadAt(data, info.Size()-size); err != nil {
+		return ""
+	}
+	text := strings.TrimSuffix(string(data), "\n")
+	line := text[strings.LastIndex(text, "\n")+1:]
+	if !strings.HasPrefix(line, auditTrailerPrefix) {
+		return ""
+	}
+	return auditField(line, "chain")
+}
+
+// startAuditChain start chain of the header
+func startAuditChain(key []byte, header string) *auditChain {
+	a := &auditChain{key: key}
+	a.chain = a.sum([]byte(header))
+	return a
+}
+
+func (a *auditChain) sum(data ...[]byte) []byte {
+	var h hash.Hash
+	if len(a.key) > 0 {
+		h = hmac.New(sha256.New, a.key)
+	} else {
+		h = sha256.New()
+	}
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// last hex of the last chain value, linked by the header of the next segment
+func (a *auditChain) last() string {
+	return hex.EncodeToString(a.chain)
+}
+
+// next chain the record text without the line end, return the audit line
+func (a *auditChain) next(text string) string {
+	a.chain = a.sum(a.chain, []byte(text))
+	a.lines++
+	return text + auditChainSep + hex.EncodeToString(a.chain) + "\n"
+}
+
+// trailer line of the segment, signed by the chain
+func (a *auditChain) trailer() string {
+	end := "lines=" + strconv.Itoa(a.lines) + " chain=" + hex.EncodeToString(a.chain)
+	return auditTrailerPrefix + end + " sig=" + hex.EncodeToString(a.sum(a.chain, []byte(end))) + "\n"
+}
+
+// SetAudit enable audit mode, every line is chained by SHA-256,
+// or HMAC-SHA256 if key is not empty, and every file is closed by a signed trailer,
+// the header of every segment is linked to the last chain value of the previous segment, also across rotation
+func (w *FileWriter) SetAudit(audit bool, key []byte) {
+	w.audit = audit
+	w.auditKey = key
+}
+
+// Verify verify the audit file written without key, see VerifyWithKey
+func Verify(path string) error {
+	return VerifyWithKey(path, nil)
+}
+
+// VerifyWithKey verify the audit file written with the key, detects edited, deleted,
+// inserted or reordered lines and segments, returns *AuditError of the first broken line,
+// which wraps ErrAuditNoTrailer if the file or a segment in it is not closed by a trailer
+func VerifyWithKey(path string, key []byte) error {
+	_, _, err := verifyAudit(path, key)
+	return err
+}
+
+// VerifyFiles verify the audit files written without key in rotation order, see VerifyFilesWithKey
+func VerifyFiles(paths ...string) error {
+	return VerifyFilesWithKey(nil, paths...)
+}
+
+// VerifyFilesWithKey verify the audit files written with the key in rotation order,
+// detects deleted or reordered files besides VerifyWithKey, every file must be linked to the previous one,
+// except a file starts a new chain, written by a process started without the previous file
+func VerifyFilesWithKey(key []byte, paths ...string) error {
+	last := ""
+	for i, path := range paths {
+		prev, chain, err := verifyAudit(path, key)
+		if err != nil {
+			return err
+		}
+		if i > 0 && prev != "" && prev != last {
+			return &AuditError{Path: path, Line: 1, Err: errors.New("file not linked to the previous file")}
+		}
+		last = chain
+	}
+	return nil
+}
+
+// verifyAudit verify the audit file, return the link of the first header and the last chain value
+func verifyAudit(path string, key []byte) (prev string, last string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer file.Close()
+
+	fail := func(line int, err error) (string, string, error) {
+		return "", "", &AuditError{Path: path, Line: line, Err: err}
+	}
+	var (
+		a            *auditChain
+		pending      strings.Builder // record text not chained yet, records may be multiline
+		pendingStart int
+		lineNo       int
+		segments     int
+	)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", "", err
+		}
+		if line == "" && err == io.EOF {
+			break
+		}
+		lineNo++
+		line = strings.TrimSuffix(line, "\n")
+
+		if pending.Len() == 0 {
+			switch {
+			case strings.HasPrefix(line, auditHeaderPrefix):
+				if a != nil {
+					return fail(lineNo, ErrAuditNoTrailer)
+				}
+				link := auditField(line, "prev")
+				if segments == 0 {
+					prev = link
+				} else if link != last {
+					return fail(lineNo, errors.New("segment not linked to the previous segment"))
+				}
+				segments++
+				a = startAuditChain(key, line)
+				continue
+			case a == nil:
+				return fail(lineNo, errors.New("line outside audit segment"))
+			case strings.HasPrefix(line, auditTrailerPrefix):
+				if line+"\n" != a.trailer() {
+					return fail(lineNo, errors.New("trailer mismatch"))
+				}
+				last = a.last()
+				a = nil
+				continue
+			}
+			pendingStart = lineNo
+		} else {
+			pending.WriteByte('\n')
+		}
+		pending.WriteString(line)
+
+		text := pending.String()
+		if i := strings.LastIndex(text, auditChainSep); i >= 0 {
+			if chain, e := hex.DecodeString(text[i+len(auditChainSep):]); e == nil &&
+				hmac.Equal(chain, a.sum(a.chain, []byte(text[:i]))) {
+				a.chain = chain
+				a.lines++
+				pending.Reset()
+				continue
+			}
+		}
+		// the chain value may be in the next line of a multiline record
+		if pending.Len() > auditMaxRecordSize {
+			return fail(pendingStart, errors.New("chain mismatch"))
+		}
+	}
+	if pending.Len() > 0 {
+		return fail(pendingStart, errors.New("chain mismatch"))
+	}
+	if a != nil {
+		return fail(lineNo+1, ErrAuditNoTrailer)
+	}
+	return prev, last, nil
+}
@@ -0,0 +1,202 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAuditFile(t *testing.T, key string, msgs ...string) string {
+	t.Helper()
+	w, filename := newSyncFileWriter(t, FileWriterOptions{Audit: true, AuditKey: key})
+	for _, msg := range msgs {
+		if err := w.Write(&Record{level: TRANSACTION, time: "now", msg: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func editAuditFile(t *testing.T, filename string, edit func(lines []string) []string) {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := edit(strings.SplitAfter(string(data), "\n"))
+	if err = os.WriteFile(filename, []byte(strings.Join(lines, "")), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_VerifyAudit(t *testing.T) {
+	filename := writeAuditFile(t, "", "pay 1", "pay 2\nsecond line", "pay 3")
+	if err := Verify(filename); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyWithKey(filename, []byte("key")); err == nil {
+		t.Error("verify with a wrong key should fail")
+	}
+
+	keyed := writeAuditFile(t, "key", "pay 1", "pay 2")
+	if err := VerifyWithKey(keyed, []byte("key")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(keyed); err == nil {
+		t.Error("verify keyed file without key should fail")
+	}
+}
+
+func Test_VerifyAuditTampered(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(lines []string) []string
+		line int
+	}{
+		{"edited", func(l []string) []string {
+			l[2] = strings.Replace(l[2], "pay 2", "pay 9", 1)
+			return l
+		}, 3},
+		{"deleted", func(l []string) []string {
+			return append(l[:2], l[3:]...)
+		}, 3},
+		{"reordered", func(l []string) []string {
+			l[1], l[2] = l[2], l[1]
+			return l
+		}, 2},
+		{"inserted", func(l []string) []string {
+			return append(l[:2], append([]string{"now [TRANSACTION] <> pay 0\n"}, l[2:]...)...)
+		}, 3},
+		{"trailer edited", func(l []string) []string {
+			l[4] = strings.Replace(l[4], "lines=3", "lines=2", 1)
+			return l
+		}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeAuditFile(t, "key", "pay 1", "pay 2", "pay 3")
+			editAuditFile(t, filename, tt.edit)
+			err := VerifyWithKey(filename, []byte("key"))
+			var auditErr *AuditError
+			if !errors.As(err, &auditErr) {
+				t.Fatalf("verify got %v, want AuditError", err)
+			}
+			if auditErr.Line != tt.line {
+				t.Errorf("verify got %v, want line %d", err, tt.line)
+			}
+		})
+	}
+}
+
+func Test_VerifyAuditNoTrailer(t *testing.T) {
+	filename := writeAuditFile(t, "", "pay 1", "pay 2")
+	editAuditFile(t, filename, func(l []string) []string {
+		return l[:3]
+	})
+	if err := Verify(filename); !errors.Is(err, ErrAuditNoTrailer) {
+		t.Errorf("verify got %v, want %v", err, ErrAuditNoTrailer)
+	}
+}
+
+func Test_VerifyAuditSegments(t *testing.T) {
+	filename := writeAuditFile(t, "", "pay 1")
+	w := NewFileWriterWithOptions(FileWriterOptions{Filename: filename, Audit: true})
+	if err := w.Init(); err != nil {
+		t.Fatal(err)
+	}
+	w.Rotate()
+	w.Write(&Record{level: TRANSACTION, time: "now", msg: "pay 2"})
+	w.Close()
+	if err := Verify(filename); err != nil {
+		t.Error(err)
+	}
+}
+
+func appendAuditSegment(t *testing.T, filename string, msgs ...string) {
+	t.Helper()
+	w := NewFileWriterWithOptions(FileWriterOptions{Filename: filename, Audit: true})
+	if err := w.Init(); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range msgs {
+		w.Write(&Record{level: TRANSACTION, time: "now", msg: msg})
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_VerifyAuditSegmentsTampered(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(lines []string) []string
+		line int
+	}{
+		// segments of 3 lines: header, record, trailer
+		{"segment deleted", func(l []string) []string {
+			return append(l[:3], l[6:]...)
+		}, 4},
+		{"segments reordered", func(l []string) []string {
+			return append(append(append([]string{}, l[:3]...), l[6:9]...), l[3:6]...)
+		}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeAuditFile(t, "", "pay 1")
+			appendAuditSegment(t, filename, "pay 2")
+			appendAuditSegment(t, filename, "pay 3")
+			if err := Verify(filename); err != nil {
+				t.Fatal(err)
+			}
+			editAuditFile(t, filename, tt.edit)
+			var auditErr *AuditError
+			if err := Verify(filename); !errors.As(err, &auditErr) || auditErr.Line != tt.line {
+				t.Errorf("verify got %v, want line %d", err, tt.line)
+			}
+		})
+	}
+}
+
+func Test_VerifyAuditFiles(t *testing.T) {
+	clock := &stepClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	w := NewFileWriterWithOptions(FileWriterOptions{
+		Filename: filepath.Join(t.TempDir(), "audit-%H%m.log"),
+		Rotate:   true,
+		Minutely: true,
+		Audit:    true,
+		AuditKey: "key",
+	})
+	w.SetClock(clock)
+	if err := w.Init(); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			clock.now = clock.now.Add(time.Minute)
+			if err := w.Rotate(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		paths = append(paths, w.files[anyLevel].path)
+		w.Write(&Record{level: TRANSACTION, time: "now", msg: "pay"})
+	}
+	w.Close()
+
+	key := []byte("key")
+	if err := VerifyFilesWithKey(key, paths...); err != nil {
+		t.Fatal(err)
+	}
+	for _, files := range [][]string{{paths[0], paths[2]}, {paths[1], paths[0], paths[2]}} {
+		var auditErr *AuditError
+		if err := VerifyFilesWithKey(key, files...); !errors.As(err, &auditErr) || auditErr.Line != 1 {
+			t.Errorf("verify %v got %v, want not linked", files, err)
+		}
+	}
+}
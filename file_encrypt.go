@@ -0,0 +1,296 @@
+package golog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// encrypted file format, a file is one or more streams, a stream is a header and chunks:
+//
+//	header: magic "GOLOGENC" | version 1 byte | key id length 1 byte | key id | nonce prefix 7 bytes
+//	chunk:  ciphertext length 4 bytes big endian | AES-GCM ciphertext
+//
+// the nonce of a chunk is nonce prefix | chunk counter 4 bytes | final flag 1 byte,
+// the header is the additional data of every chunk, the last chunk of a stream is final
+const (
+	encryptMagic          = "GOLOGENC"
+	encryptVersion        = 1
+	encryptNoncePrefixLen = 7
+	encryptChunkSize      = 64 * 1024
+)
+
+var (
+	// ErrEncryptTruncated encrypted stream without final chunk, the file is being written,
+	// the writer crashed, or the file is truncated
+	ErrEncryptTruncated = errors.New("encrypted stream truncated")
+	// ErrEncryptFormat invalid encrypted file
+	ErrEncryptFormat = errors.New("invalid encrypted stream")
+)
+
+// KeyFunc return the AES key of key id, 16, 24 or 32 bytes for AES-128, AES-192 or AES-256
+type KeyFunc func(keyID string) ([]byte, error)
+
+var (
+	encryptKeyFunc     KeyFunc
+	encryptKeyFuncLock sync.RWMutex
+)
+
+// SetEncryptKeyFunc set the key func of file writers encrypted by options
+func SetEncryptKeyFunc(f KeyFunc) {
+	encryptKeyFuncLock.Lock()
+	defer encryptKeyFuncLock.Unlock()
+	encryptKeyFunc = f
+}
+
+func getEncryptKeyFunc() KeyFunc {
+	encryptKeyFuncLock.RLock()
+	defer encryptKeyFuncLock.RUnlock()
+	return encryptKeyFunc
+}
+
+// newStreamCipher create AES-GCM of the key id
+func newStreamCipher(keyID string, keyFunc KeyFunc) (cipher.AEAD, error) {
+	if keyFunc == nil {
+		return nil, errors.New("no encrypt key func")
+	}
+	key, err := keyFunc(keyID)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce nonce of the chunk
+func chunkNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 12)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[encryptNoncePrefixLen:], counter)
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptWriter encrypt data into a stream of chunks, a chunk is written on flush or when the buffer is full
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+}
+
+// newEncryptWriter start a stream with the header written to w
+func newEncryptWriter(w io.Writer, keyID string, keyFunc KeyFunc) (*encryptWriter, error) {
+	if len(keyID) > 255 {
+		return nil, errors.New("encrypt key id longer than 255")
+	}
+	aead, err := newStreamCipher(keyID, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	prefix := make([]byte, encryptNoncePrefixLen)
+	if _, err = rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	header := append([]byte(encryptMagic), encryptVersion, byte(len(keyID)))
+	header = append(append(header, keyID...), prefix...)
+	if _, err = w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{w: w, aead: aead, header: header, prefix: prefix}, nil
+}
+
+// Write buffer data, full chunks are written
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		size := encryptChunkSize - len(e.buf)
+		if size > len(p) {
+			size = len(p)
+		}
+		e.buf = append(e.buf, p[:size]...)
+		p = p[size:]
+		if len(e.buf) == encryptChunkSize {
+			if err := e.writeChunk(false); err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+// flush write buffered data as a chunk
+func (e *encryptWriter) flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	return e.writeChunk(false)
+}
+
+// close write buffered data as the final chunk
+func (e *encryptWriter) close() error {
+	return e.writeChunk(true)
+}
+
+func (e *encryptWriter) writeChunk(final bool) error {
+	if e.counter == ^uint32(0) {
+		return errors.New("too many encrypted chunks")
+	}
+	sealed := e.aead.Seal(make([]byte, 4, 4+len(e.buf)+e.aead.Overhead()),
+		chunkNonce(e.prefix, e.counter, final), e.buf, e.header)
+	binary.BigEndian.PutUint32(sealed, uint32(len(sealed)-4))
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// SetEncrypt encrypt files by the key of key id, keyFunc is called on every file opened
+func (w *FileWriter) SetEncrypt(keyID string, keyFunc KeyFunc) {
+	w.encryptKeyID = keyID
+	w.encryptKeyFunc = keyFunc
+}
+
+// decryptReader decrypt streams of chunks
+type decryptReader struct {
+	r       *bufio.Reader
+	keyFunc KeyFunc
+	aead    cipher.AEAD // nil before header or after the final chunk
+	header  []byte
+	prefix  []byte
+	counter uint32
+	plain   []byte
+	err     error
+	cutOff  bool // a stream is cut off by the header of the next stream, like the writer crashed
+}
+
+// NewDecryptReader create reader decrypts the encrypted file content,
+// returns ErrEncryptTruncated if a stream is not closed by the final chunk.
+// A stream cut off by the next stream is read up to the cut, then the next streams are read,
+// and ErrEncryptTruncated is returned at the end
+func NewDecryptReader(r io.Reader, keyFunc KeyFunc) io.Reader {
+	return &decryptReader{r: bufio.NewReader(r), keyFunc: keyFunc}
+}
+
+// DecryptFile decrypt the encrypted file and write the content to w
+func DecryptFile(path string, w io.Writer, keyFunc KeyFunc) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, NewDecryptReader(file, keyFunc))
+	return err
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.next()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next read the next header or chunk
+func (d *decryptReader) next() error {
+	if d.aead == nil {
+		err := d.readHeader()
+		if err == io.EOF && d.cutOff {
+			return ErrEncryptTruncated
+		}
+		return err
+	}
+	// the size of a chunk never starts with the magic
+	if b, err := d.r.Peek(len(encryptMagic)); err == nil && string(b) == encryptMagic {
+		return d.resync(nil, nil)
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(d.r, size[:]); err != nil {
+		return ErrEncryptTruncated
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n < uint32(d.aead.Overhead()) || n > encryptChunkSize+uint32(d.aead.Overhead()) {
+		return d.resync(size[:], ErrEncryptFormat)
+	}
+	sealed := make([]byte, n)
+	if m, err := io.ReadFull(d.r, sealed); err != nil {
+		return d.resync(append(size[:], sealed[:m]...), ErrEncryptTruncated)
+	}
+	final := false
+	plain, err := d.aead.Open(nil, chunkNonce(d.prefix, d.counter, false), sealed, d.header)
+	if err != nil {
+		final = true
+		if plain, err = d.aead.Open(nil, chunkNonce(d.prefix, d.counter, true), sealed, d.header); err != nil {
+			return d.resync(append(size[:], sealed...), errors.New("encrypted chunk authentication failed"))
+		}
+	}
+	d.counter++
+	d.plain = plain
+	if final {
+		d.aead = nil
+	}
+	return nil
+}
+
+// resync continue from the header of the next stream in the data read or at the reader,
+// the current stream is cut off there, like a chunk partly written by a crashed writer. Or return err
+func (d *decryptReader) resync(read []byte, err error) error {
+	if read != nil {
+		i := bytes.Index(read, []byte(encryptMagic))
+		if i < 0 {
+			return err
+		}
+		d.r = bufio.NewReader(io.MultiReader(bytes.NewReader(read[i:]), d.r))
+	}
+	d.cutOff = true
+	d.aead = nil
+	return nil
+}
+
+// readHeader read header of the next stream, io.EOF if no more stream
+func (d *decryptReader) readHeader() error {
+	magic := make([]byte, len(encryptMagic)+2)
+	if n, err := io.ReadFull(d.r, magic); err != nil {
+		if n == 0 && err == io.EOF {
+			return io.EOF
+		}
+		return ErrEncryptFormat
+	}
+	if string(magic[:len(encryptMagic)]) != encryptMagic || magic[len(encryptMagic)] != encryptVersion {
+		return ErrEncryptFormat
+	}
+	rest := make([]byte, int(magic[len(magic)-1])+encryptNoncePrefixLen)
+	if _, err := io.ReadFull(d.r, rest); err != nil {
+		return ErrEncryptFormat
+	}
+	keyID := string(rest[:len(rest)-encryptNoncePrefixLen])
+	aead, err := newStreamCipher(keyID, d.keyFunc)
+	if err != nil {
+		return err
+	}
+	d.aead = aead
+	d.header = append(magic, rest...)
+	d.prefix = rest[len(rest)-encryptNoncePrefixLen:]
+	d.counter = 0
+	return nil
+}
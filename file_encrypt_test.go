@@ -0,0 +1,135 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func testKeyFunc(keyID string) ([]byte, error) {
+	if keyID != "k1" {
+		return nil, errors.New("unknown key " + keyID)
+	}
+	return bytes.Repeat([]byte{1}, 32), nil
+}
+
+func writeEncryptedFile(t *testing.T, msgs ...string) (*FileWriter, string) {
+	t.Helper()
+	w, filename := newSyncFileWriter(t, FileWriterOptions{})
+	w.Close()
+	w.SetEncrypt("k1", testKeyFunc)
+	w.Rotate()
+	for _, msg := range msgs {
+		if err := w.Write(&Record{level: COMMON, time: "now", msg: msg}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w, filename
+}
+
+func decryptString(t *testing.T, filename string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	err := DecryptFile(filename, &out, testKeyFunc)
+	return out.String(), err
+}
+
+func Test_EncryptFileWriter(t *testing.T) {
+	w, filename := writeEncryptedFile(t, "secret 1", "secret 2")
+	w.Flush()
+	data, _ := os.ReadFile(filename)
+	if bytes.Contains(data, []byte("secret")) {
+		t.Fatal("file is not encrypted")
+	}
+	if _, err := decryptString(t, filename); !errors.Is(err, ErrEncryptTruncated) {
+		t.Errorf("decrypt open file got %v, want %v", err, ErrEncryptTruncated)
+	}
+
+	// reopen appends a new stream
+	w.Close()
+	w.Rotate()
+	w.Write(&Record{level: COMMON, time: "now", msg: "secret 3"})
+	w.Close()
+	got, err := decryptString(t, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "now [COMMON] <> secret 1\nnow [COMMON] <> secret 2\nnow [COMMON] <> secret 3\n"
+	if got != want {
+		t.Errorf("decrypt got %q, want %q", got, want)
+	}
+}
+
+func Test_EncryptTampered(t *testing.T) {
+	w, filename := writeEncryptedFile(t, "secret")
+	w.Close()
+	data, _ := os.ReadFile(filename)
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)-1] ^= 1
+	os.WriteFile(filename, flipped, 0644)
+	if _, err := decryptString(t, filename); err == nil {
+		t.Error("decrypt tampered file should fail")
+	}
+
+	os.WriteFile(filename, append(data, data[:10]...), 0644)
+	if _, err := decryptString(t, filename); !errors.Is(err, ErrEncryptFormat) {
+		t.Errorf("decrypt got %v, want %v", err, ErrEncryptFormat)
+	}
+}
+
+func Test_EncryptChunks(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := newEncryptWriter(&buf, "k1", testKeyFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := strings.Repeat("0123456789", encryptChunkSize/5)
+	e.Write([]byte(plain))
+	e.close()
+	got, err := io.ReadAll(NewDecryptReader(&buf, testKeyFunc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != plain {
+		t.Errorf("decrypt got %d bytes, want %d", len(got), len(plain))
+	}
+
+	if _, err = newEncryptWriter(&buf, "k2", testKeyFunc); err == nil {
+		t.Error("encrypt with unknown key should fail")
+	}
+}
+
+func Test_EncryptCrashRecovery(t *testing.T) {
+	for _, partial := range []int64{0, 10} {
+		w, filename := writeEncryptedFile(t, "secret 1")
+		w.Flush()
+		if partial > 0 {
+			// the writer crashes while writing the chunk of "secret 2"
+			info, _ := os.Stat(filename)
+			w.Write(&Record{level: COMMON, time: "now", msg: "secret 2"})
+			w.Flush()
+			if err := os.Truncate(filename, info.Size()+partial); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// a new writer appends a new stream
+		w2 := NewFileWriterWithOptions(FileWriterOptions{Filename: filename})
+		w2.SetEncrypt("k1", testKeyFunc)
+		w2.Rotate()
+		w2.Write(&Record{level: COMMON, time: "now", msg: "secret 3"})
+		w2.Close()
+
+		got, err := decryptString(t, filename)
+		if !errors.Is(err, ErrEncryptTruncated) {
+			t.Errorf("partial %d: decrypt err = %v, want %v", partial, err, ErrEncryptTruncated)
+		}
+		if want := "now [COMMON] <> secret 1\nnow [COMMON] <> secret 3\n"; got != want {
+			t.Errorf("partial %d: decrypt got %q, want %q", partial, got, want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package golog
+
+import "os"
+
+// lockFile advisory lock is not supported, records are still appended by a single write
+func lockFile(*os.File) error {
+	return nil
+}
+
+// unlockFile advisory lock is not supported
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,94 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// writeLocked write text to file by a single append under the advisory lock,
+// so records of processes sharing the file never interleave
+func (f *logFile) writeLocked(text string) (int, error) {
+	if err := lockFile(f.file); err != nil {
+		return 0, err
+	}
+	n, err := f.file.WriteString(text)
+	if e := unlockFile(f.file); e != nil && err == nil {
+		err = e
+	}
+	return n, err
+}
+
+// sharedLockPath path of the lock file of shared files, beside the files,
+// in the last directory of the pattern without path variables
+func sharedLockPath(pattern string) string {
+	prefix := pattern
+	if i := strings.Index(pattern, "%"); i >= 0 {
+		prefix = pattern[:i]
+	}
+	return filepath.Join(filepath.Dir(prefix), "."+filepath.Base(pattern)+".lock")
+}
+
+// sharedRotate rotate under the advisory lock of the lock file, which keeps the time of the current files.
+// The time is re-checked under the lock: files rotated by another process are reopened, not rotated again
+func (w *FileWriter) sharedRotate(now time.Time) error {
+	lockPath := sharedLockPath(w.filename)
+	if err := os.MkdirAll(filepath.Dir(lockPath), w.rotatePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err = lockFile(file); err != nil {
+		return err
+	}
+	defer unlockFile(file)
+
+	last, ok := readSharedTime(file, now.Location())
+	adopt := ok && (!w.initFileOk || last.After(w.lastWriteTime))
+	if !adopt {
+		last, ok = w.lastWriteTime, w.initFileOk
+	}
+	if ok && !w.rotateDue(last, now) {
+		if !adopt {
+			return nil
+		}
+		// rotated by another process
+		return w.rotateAt(last)
+	}
+	if err = writeSharedTime(file, now); err != nil {
+		return err
+	}
+	return w.rotateAt(now)
+}
+
+// readSharedTime read the time of the current files from the lock file, false if not written yet
+func readSharedTime(file *os.File, loc *time.Location) (time.Time, bool) {
+	data := make([]byte, 32)
+	n, _ := file.ReadAt(data, 0)
+	nanos, err := strconv.ParseInt(strings.TrimSpace(string(data[:n])), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, nanos).In(loc), true
+}
+
+// writeSharedTime write the time of the current files to the lock file
+func writeSharedTime(file *os.File, t time.Time) error {
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := file.WriteAt([]byte(strconv.FormatInt(t.UnixNano(), 10)+"\n"), 0)
+	return err
+}
+
+// SetShared share files with other processes, records are appended by a single write under
+// the advisory lock, and rotations are decided under the advisory lock of a lock file beside the files,
+// audit and encryption are not supported
+func (w *FileWriter) SetShared(shared bool) {
+	w.shared = shared
+}
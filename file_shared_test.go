@@ -0,0 +1,123 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_FileWriterSharedAppend(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "shared.log")
+	msg := strings.Repeat("x", 16*1024)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		// writers of separate opened files lock each other like processes
+		w := NewFileWriterWithOptions(FileWriterOptions{Filename: filename, Shared: true})
+		if err := w.Init(); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer w.Close()
+			for j := 0; j < 50; j++ {
+				if err := w.Write(&Record{level: COMMON, time: "now", msg: msg}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 200 {
+		t.Fatalf("lines got %d, want 200", len(lines))
+	}
+	for i, line := range lines {
+		if line != "now [COMMON] <> "+msg {
+			t.Fatalf("line %d interleaved", i+1)
+		}
+	}
+}
+
+func Test_FileWriterSharedRotation(t *testing.T) {
+	dir := t.TempDir()
+	options := FileWriterOptions{
+		Filename: filepath.Join(dir, "app-%Y%M%D%H%m.log"),
+		Rotate:   true,
+		Daily:    true,
+		MaxDays:  1,
+		Shared:   true,
+	}
+	// writers of the same files like processes started at different times
+	var writers []*FileWriter
+	var clocks []*stepClock
+	for i := 0; i < 2; i++ {
+		clocks = append(clocks, &stepClock{})
+		w := NewFileWriterWithOptions(options)
+		w.SetClock(clocks[i])
+		writers = append(writers, w)
+		defer w.Close()
+	}
+	steps := []struct {
+		writer int
+		now    time.Time
+		want   string
+	}{
+		{0, time.Date(2023, 1, 1, 10, 30, 0, 0, time.UTC), "app-202301011030.log"},
+		{1, time.Date(2023, 1, 1, 20, 0, 0, 0, time.UTC), "app-202301011030.log"}, // opens the current file
+		{0, time.Date(2023, 1, 2, 0, 1, 0, 0, time.UTC), "app-202301020001.log"},
+		{1, time.Date(2023, 1, 2, 0, 2, 0, 0, time.UTC), "app-202301020001.log"}, // rotated by writer 0
+		{1, time.Date(2023, 1, 2, 0, 3, 0, 0, time.UTC), "app-202301020001.log"},
+	}
+	for i, step := range steps {
+		w := writers[step.writer]
+		clocks[step.writer].now = step.now
+		var err error
+		if w.initFileOk {
+			err = w.Rotate()
+		} else {
+			err = w.Init()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filepath.Base(w.files[anyLevel].path); got != step.want {
+			t.Errorf("step %d path got %s, want %s", i, got, step.want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".app-%Y%M%D%H%m.log.lock")); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test_sharedLockPath(t *testing.T) {
+	tests := map[string]string{
+		"app.log":                ".app.log.lock",
+		"./logs/app-%Y%M%D.log":  "logs/.app-%Y%M%D.log.lock",
+		"/var/log/%Y/%M/app.log": "/var/log/.app.log.lock",
+	}
+	for pattern, want := range tests {
+		if got := sharedLockPath(pattern); got != want {
+			t.Errorf("sharedLockPath(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func Test_FileWriterOptionsValidateShared(t *testing.T) {
+	options := FileWriterOptions{Filename: "app.log", Shared: true, Audit: true}
+	if err := options.validate(); err == nil || err.Error() != "shared: not supported with audit" {
+		t.Errorf("validate got %v", err)
+	}
+}
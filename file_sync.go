@@ -0,0 +1,102 @@
+package golog
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// sync modes of file writer, decide when the written records are fsynced to disk
+const (
+	SyncNone     = "none"     // never fsync, the system decides, default
+	SyncAlways   = "always"   // fsync every record
+	SyncLevel    = "level"    // fsync records of the sync levels
+	SyncInterval = "interval" // fsync on interval
+)
+
+// fileBufferSizeDefault default buffer size of file writer
+const fileBufferSizeDefault = 8192
+
+// syncFile fsync the file, replaced by go test to count fsyncs
+var syncFile = (*os.File).Sync
+
+// parseSyncMode parse sync mode, "" for SyncNone
+func parseSyncMode(mode string) (string, error) {
+	switch mode {
+	case "", SyncNone:
+		return SyncNone, nil
+	case SyncAlways, SyncLevel, SyncInterval:
+		return mode, nil
+	default:
+		return "", errors.New("invalid sync mode (" + mode + ")")
+	}
+}
+
+// sync flush buffered data and fsync file to disk
+func (f *logFile) sync() error {
+	if err := f.flush(); err != nil {
+		return err
+	}
+	return syncFile(f.file)
+}
+
+// syncAfterWrite fsync file after the record of level written by the sync mode
+func (w *FileWriter) syncAfterWrite(f *logFile, level int) error {
+	switch w.syncMode {
+	case SyncAlways:
+		return f.sync()
+	case SyncLevel:
+		if w.syncLevels.Contains(level) {
+			return f.sync()
+		}
+	case SyncInterval:
+		return w.syncOnInterval()
+	}
+	return nil
+}
+
+// syncOnInterval fsync all files if the sync interval passed since the last sync
+func (w *FileWriter) syncOnInterval() error {
+	now := clockOrSystem(w.clock).Now()
+	if now.Sub(w.lastSyncTime) < w.syncInterval {
+		return nil
+	}
+	w.lastSyncTime = now
+	return w.Sync()
+}
+
+// Sync flush buffered data and fsync all opened files to disk
+func (w *FileWriter) Sync() error {
+	var err error
+	for _, f := range w.files {
+		if e := f.sync(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// SetBufferSize set the buffer size of files, 0 for the default size, should call before Init
+func (w *FileWriter) SetBufferSize(size int) {
+	w.bufferSize = size
+}
+
+// SetSyncMode set the sync mode, SyncNone by default
+func (w *FileWriter) SetSyncMode(mode string) error {
+	mode, err := parseSyncMode(mode)
+	if err != nil {
+		return err
+	}
+	w.syncMode = mode
+	return nil
+}
+
+// SetSyncLevels set levels of records fsynced in SyncLevel mode
+func (w *FileWriter) SetSyncLevels(levels LevelSet) {
+	w.syncLevels = levels
+}
+
+// SetSyncInterval set interval to fsync files in SyncInterval mode
+func (w *FileWriter) SetSyncInterval(interval time.Duration) {
+	w.syncInterval = interval
+}
@@ -0,0 +1,152 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	return c.now
+}
+
+func newSyncFileWriter(t *testing.T, options FileWriterOptions) (*FileWriter, string) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "sync.log")
+	options.Filename = filename
+	if err := options.validate(); err != nil {
+		t.Fatal(err)
+	}
+	w := NewFileWriterWithOptions(options)
+	if err := w.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w, filename
+}
+
+func fileSize(t *testing.T, filename string) int64 {
+	t.Helper()
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+// countSyncs count fsyncs of files until the test ends
+func countSyncs(t *testing.T) *int {
+	t.Helper()
+	var n int
+	syncFile = func(f *os.File) error {
+		n++
+		return f.Sync()
+	}
+	t.Cleanup(func() { syncFile = (*os.File).Sync })
+	return &n
+}
+
+func Test_FileWriterSyncModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		options FileWriterOptions
+		level   int
+		synced  bool
+	}{
+		{"none", FileWriterOptions{}, TRANSACTION, false},
+		{"always", FileWriterOptions{Sync: SyncAlways}, DEBUG, true},
+		{"level synced", FileWriterOptions{Sync: SyncLevel, SyncLevels: []string{"transaction"}}, TRANSACTION, true},
+		{"level not synced", FileWriterOptions{Sync: SyncLevel, SyncLevels: []string{"transaction"}}, COMMON, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, filename := newSyncFileWriter(t, tt.options)
+			syncs := countSyncs(t)
+			for i := 0; i < 2; i++ {
+				if err := w.Write(&Record{level: tt.level, time: "now", msg: "paid"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if synced := fileSize(t, filename) > 0; synced != tt.synced {
+				t.Errorf("synced got %v, want %v", synced, tt.synced)
+			}
+			want := 0
+			if tt.synced {
+				want = 2
+			}
+			if *syncs != want {
+				t.Errorf("fsyncs got %d, want %d", *syncs, want)
+			}
+		})
+	}
+}
+
+func Test_FileWriterSyncInterval(t *testing.T) {
+	clock := &stepClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	w, filename := newSyncFileWriter(t, FileWriterOptions{Sync: SyncInterval, SyncInterval: "1s"})
+	w.SetClock(clock)
+	syncs := countSyncs(t)
+	r := &Record{level: COMMON, time: "now", msg: "paid"}
+
+	if err := w.Write(r); err != nil {
+		t.Fatal(err)
+	}
+	size := fileSize(t, filename)
+	if size == 0 || *syncs != 1 {
+		t.Fatalf("first write should be synced, fsyncs %d", *syncs)
+	}
+	synced := w.lastSyncTime
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	w.Write(r)
+	w.Flush()
+	if fileSize(t, filename) != 2*size {
+		t.Error("not flushed before the interval passed")
+	}
+	if !w.lastSyncTime.Equal(synced) || *syncs != 1 {
+		t.Errorf("fsynced before the interval passed, fsyncs %d", *syncs)
+	}
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	w.Flush()
+	if !w.lastSyncTime.Equal(clock.now) || *syncs != 2 {
+		t.Errorf("not fsynced after the interval passed, fsyncs %d", *syncs)
+	}
+}
+
+func Test_FileWriterBufferSize(t *testing.T) {
+	w, filename := newSyncFileWriter(t, FileWriterOptions{BufferSize: 16})
+	if err := w.Write(&Record{level: COMMON, time: "now", msg: "longer than the buffer"}); err != nil {
+		t.Fatal(err)
+	}
+	if fileSize(t, filename) == 0 {
+		t.Error("record longer than buffer should be written through")
+	}
+}
+
+func Test_FileWriterOptionsValidateSync(t *testing.T) {
+	tests := []struct {
+		options FileWriterOptions
+		want    string
+	}{
+		{FileWriterOptions{Sync: "never"}, "sync: invalid sync mode (never)"},
+		{FileWriterOptions{Sync: SyncLevel}, "sync_levels: required by sync mode level"},
+		{FileWriterOptions{Sync: SyncLevel, SyncLevels: []string{"bad"}}, "sync_levels: invalid level flag (bad)"},
+		{FileWriterOptions{Sync: SyncInterval}, "sync_interval: required by sync mode interval"},
+		{FileWriterOptions{Sync: SyncInterval, SyncInterval: "0s"}, "sync_interval: non-positive interval 0s"},
+		{FileWriterOptions{BufferSize: -1}, "buffer_size: negative value -1"},
+	}
+	for _, tt := range tests {
+		tt.options.Filename = "app.log"
+		err := tt.options.validate()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("validate got %v, want %s", err, tt.want)
+		}
+	}
+}
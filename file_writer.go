@@ -2,9 +2,7 @@ package golog
 
 import (
 	"bufio"
-	"bytes"
 	"errors"
-	"fmt"
 	"log"
 	"os"
 	"path"
@@ -12,16 +10,73 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var pathVariableTable map[byte]func(*time.Time) int
+// anyLevel key of the file for all levels, when the path pattern has no level variable
+const anyLevel = -1
+
+// logFile file opened by file writer
+type logFile struct {
+	path      string
+	file      *os.File
+	bufWriter *bufio.Writer
+	audit     *auditChain    // chain of audit mode
+	enc       *encryptWriter // encrypt data flushed by bufWriter if set
+	shared    bool           // write records unbuffered under the advisory lock
+}
+
+// write record text to file, chained in audit mode, return the bytes written
+func (f *logFile) write(text string) (int, error) {
+	if f.audit != nil {
+		text = f.audit.next(strings.TrimSuffix(text, "\n"))
+	}
+	if f.shared {
+		return f.writeLocked(text)
+	}
+	return f.bufWriter.WriteString(text)
+}
+
+// flush writes any buffered data to file
+func (f *logFile) flush() error {
+	if err := f.bufWriter.Flush(); err != nil {
+		return err
+	}
+	if f.enc != nil {
+		return f.enc.flush()
+	}
+	return nil
+}
+
+// close flush buffered data and close file, fsync before close if sync
+func (f *logFile) close(sync bool) error {
+	if f.audit != nil {
+		if _, err := f.bufWriter.WriteString(f.audit.trailer()); err != nil {
+			return err
+		}
+	}
+	if err := f.bufWriter.Flush(); err != nil {
+		return err
+	}
+	if f.enc != nil {
+		if err := f.enc.close(); err != nil {
+			return err
+		}
+	}
+	if sync {
+		if err := syncFile(f.file); err != nil {
+			return err
+		}
+	}
+	return f.file.Close()
+}
 
 // FileWriter file writer for log record deal
 type FileWriter struct {
 	// write log order by order and atomic incr
 	// maxLinesCurLines and maxSizeCurSize
-	level        int
+	levels       LevelSet
 	lock         sync.RWMutex
 	initFileOnce sync.Once // init once
 
@@ -29,15 +84,13 @@ type FileWriter struct {
 	perm       string      // input
 	// input filename
 	filename string
-	// The opened file
-	file          *os.File
-	fileBufWriter *bufio.Writer
-	// like "test.log", test is filenameOnly and .log is suffix
+	// The opened files by level, or by anyLevel if the path pattern has no level variable
+	files map[int]*logFile
+	// like "test.log", test is filenameOnly and .log is suffix, of the file opened last
 	filenameOnly, suffix string
 
-	pathFmt   string // Rotate when, use actions
-	actions   []func(*time.Time) int
-	variables []interface{}
+	pattern pathPattern // Rotate when time variables change
+	symlink pathPattern // symlink to the current file, repointed on file opened
 
 	// // Rotate at file lines
 	// maxLines         int // Rotate at line
@@ -47,7 +100,9 @@ type FileWriter struct {
 	// maxSize        int
 	// maxSizeCurSize int
 
-	lastWriteTime time.Time
+	lastWriteTime time.Time      // time of the last rotation, path variables are formatted by it
+	clock         Clock          // time source of rotation, SystemClock if nil
+	location      *time.Location // location of rotation and path variables, the clock's location if nil
 
 	initFileOk bool
 	rotate     bool
@@ -57,27 +112,46 @@ type FileWriter struct {
 	hourly bool
 	// Rotate minutely
 	minutely bool
+	// Rotate secondly
+	secondly bool
 
-	maxDays       int
-	dailyOpenDate int
-	dailyOpenTime time.Time
-
+	maxDays int
 	// Rotate hourly
-	maxHours       int
-	hourlyOpenDate int
-	hourlyOpenTime time.Time
-
+	maxHours int
 	// Rotate minutely
-	maxMinutes       int
-	minutelyOpenDate int
-	minutelyOpenTime time.Time
+	maxMinutes int
+	// Rotate secondly
+	maxSeconds int
+
+	bufferSize   int           // buffer size of files
+	syncMode     string        // when to fsync files
+	syncLevels   LevelSet      // levels of records fsynced in SyncLevel mode
+	syncInterval time.Duration // interval to fsync files in SyncInterval mode
+	lastSyncTime time.Time
+
+	audit     bool           // chain lines and close files by signed trailer
+	auditKey  []byte         // HMAC key of audit mode
+	auditPrev map[int]string // last chain value of the closed files by level, linked by the next files
+
+	encryptKeyID   string  // encrypt files by the key of id if set
+	encryptKeyFunc KeyFunc // key func of encryption, the func by SetEncryptKeyFunc if nil
+
+	shared bool // share files with other processes
+
+	bytesWritten uint64 // atomic, bytes written to files
+	rotations    uint64 // atomic, count of rotations
 }
 
 // FileWriterOptions file writer options
 type FileWriterOptions struct {
+	// Name of the writer, for logs and config errors
+	Name     string `json:"name" mapstructure:"name"`
 	Level    string `json:"level" mapstructure:"level"`
 	Filename string `json:"filename" mapstructure:"filename"`
 	Enable   bool   `json:"enable" mapstructure:"enable"`
+	// Levels explicit levels or level ranges accepted, overrides Level if set,
+	// like ["access"] or ["error-debug"]
+	Levels []string `json:"levels" mapstructure:"levels"`
 
 	Rotate bool `json:"rotate" mapstructure:"rotate"`
 	// Rotate daily
@@ -86,25 +160,59 @@ type FileWriterOptions struct {
 	Hourly bool `json:"hourly" mapstructure:"hourly"`
 	// Rotate minutely
 	Minutely bool `json:"minutely" mapstructure:"minutely"`
+	// Rotate secondly, by %s in filename, checked on every write
+	Secondly bool `json:"secondly" mapstructure:"secondly"`
 
 	MaxDays    int `json:"max_days" mapstructure:"max_days"`
 	MaxHours   int `json:"max_hours" mapstructure:"max_hours"`
 	MaxMinutes int `json:"max_minutes" mapstructure:"max_minutes"`
+	MaxSeconds int `json:"max_seconds" mapstructure:"max_seconds"`
+
+	// Location of rotation and path variables, like "UTC", "Local" or "Asia/Shanghai",
+	// the location of LogConfig is used if not set
+	Location string `json:"location" mapstructure:"location"`
+
+	// Symlink to the current file, like "./logs/app.log", may contain %L for files by level
+	Symlink string `json:"symlink" mapstructure:"symlink"`
+
+	// BufferSize buffer size of files, 8192 by default
+	BufferSize int `json:"buffer_size" mapstructure:"buffer_size"`
+	// Sync when to fsync files: "none" by default, "always", "level" or "interval"
+	Sync string `json:"sync" mapstructure:"sync"`
+	// SyncLevels levels or level ranges of records fsynced in "level" mode, like ["transaction"]
+	SyncLevels []string `json:"sync_levels" mapstructure:"sync_levels"`
+	// SyncInterval interval to fsync files in "interval" mode, like "1s"
+	SyncInterval string `json:"sync_interval" mapstructure:"sync_interval"`
+
+	// Audit chain every line by SHA-256 and close every file by a signed trailer, see Verify
+	Audit bool `json:"audit" mapstructure:"audit"`
+	// AuditKey HMAC key of audit mode, chain lines by HMAC-SHA256 if set
+	AuditKey string `json:"audit_key" mapstructure:"audit_key"`
+
+	// EncryptKeyID encrypt files by AES-GCM with the key of id returned by the func of SetEncryptKeyFunc,
+	// see DecryptFile
+	EncryptKeyID string `json:"encrypt_key_id" mapstructure:"encrypt_key_id"`
+
+	// Shared share files with other processes, like pre-forked workers, records are appended whole
+	// under an advisory file lock, and rotations are decided under the lock of a lock file beside the files,
+	// which keeps the time of the current files, so files rotated by another process are opened instead
+	Shared bool `json:"shared" mapstructure:"shared"`
 }
 
 // NewFileWriter create new file writer
 func NewFileWriter() *FileWriter {
-	return &FileWriter{}
+	return &FileWriter{levels: LevelSetAll}
 }
 
 // NewFileWriterWithOptions create new file writer with options
 func NewFileWriterWithOptions(options FileWriterOptions) *FileWriter {
-	defaultLevel := DEBUG
-	if len(options.Level) > 0 {
-		defaultLevel = getLevelDefault(options.Level, defaultLevel, "")
-	}
+	return newFileWriter(options, DEBUG, WriterTypeFile)
+}
+
+// newFileWriter create file writer, up to defaultLevel if no level is set
+func newFileWriter(options FileWriterOptions, defaultLevel int, name string) *FileWriter {
 	fileWriter := &FileWriter{
-		level:      defaultLevel,
+		levels:     writerLevels(options.Level, options.Levels, defaultLevel, name),
 		filename:   options.Filename,
 		rotate:     options.Rotate,
 		daily:      options.Daily,
@@ -113,22 +221,192 @@ func NewFileWriterWithOptions(options FileWriterOptions) *FileWriter {
 		maxHours:   options.MaxHours,
 		minutely:   options.Minutely,
 		maxMinutes: options.MaxMinutes,
+		secondly:   options.Secondly,
+		maxSeconds: options.MaxSeconds,
+		bufferSize: options.BufferSize,
+		audit:      options.Audit,
+
+		encryptKeyID: options.EncryptKeyID,
+		shared:       options.Shared,
+	}
+	if options.AuditKey != "" {
+		fileWriter.auditKey = []byte(options.AuditKey)
+	}
+	if err := fileWriter.SetSyncMode(options.Sync); err != nil {
+		log.Printf("[go-log] file writer sync err: %v", err.Error())
+	}
+	if len(options.SyncLevels) > 0 {
+		if s, err := ParseLevelSet(options.SyncLevels); err == nil {
+			fileWriter.syncLevels = s
+		} else {
+			log.Printf("[go-log] file writer sync levels err: %v", err.Error())
+		}
+	}
+	if options.SyncInterval != "" {
+		if d, err := time.ParseDuration(options.SyncInterval); err == nil {
+			fileWriter.syncInterval = d
+		} else {
+			log.Printf("[go-log] file writer sync interval err: %v", err.Error())
+		}
 	}
 	if err := fileWriter.SetPathPattern(options.Filename); err != nil {
 		log.Printf("[go-log] file writer init err: %v", err.Error())
 	}
+	if loc, err := LoadLocation(options.Location); err == nil {
+		fileWriter.location = loc
+	} else {
+		log.Printf("[go-log] file writer location err: %v", err.Error())
+	}
+	if err := fileWriter.SetSymlink(options.Symlink); err != nil {
+		log.Printf("[go-log] file writer symlink err: %v", err.Error())
+	}
 	return fileWriter
 }
 
 // Write file write
 func (w *FileWriter) Write(r *Record) error {
-	if r.level > w.level {
+	if !w.levels.Contains(r.level) {
 		return nil
 	}
-	if w.fileBufWriter == nil {
-		return errors.New("fileWriter no opened file: " + w.filename)
+	// rotations by second can't wait for the rotate timer
+	if w.secondly && w.initFileOk {
+		if err := w.Rotate(); err != nil {
+			return err
+		}
+	}
+	f, err := w.fileOf(r.level)
+	if err != nil {
+		return err
+	}
+	n, err := f.write(r.String())
+	atomic.AddUint64(&w.bytesWritten, uint64(n))
+	if err != nil {
+		return err
+	}
+	return w.syncAfterWrite(f, r.level)
+}
+
+// fileOf return the file of level, the file is opened on the first record of level
+func (w *FileWriter) fileOf(level int) (*logFile, error) {
+	key := anyLevel
+	if w.pattern.hasLevel() {
+		key = level
+	}
+	if f, ok := w.files[key]; ok {
+		return f, nil
+	}
+	if len(w.pattern) == 0 || !w.initFileOk {
+		return nil, errors.New("fileWriter no opened file: " + w.filename)
+	}
+	return w.openFile(key)
+}
+
+// openFile open file of the level by the time of the last rotation
+func (w *FileWriter) openFile(level int) (*logFile, error) {
+	filePath := w.pattern.format(w.lastWriteTime, level)
+
+	if err := os.MkdirAll(path.Dir(filePath), w.rotatePerm); err != nil {
+		if !os.IsExist(err) {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, w.rotatePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	size := w.bufferSize
+	if size <= 0 {
+		size = fileBufferSizeDefault
+	}
+	f := &logFile{path: filePath, file: file, shared: w.shared}
+	if w.encryptKeyID != "" {
+		keyFunc := w.encryptKeyFunc
+		if keyFunc == nil {
+			keyFunc = getEncryptKeyFunc()
+		}
+		if f.enc, err = newEncryptWriter(file, w.encryptKeyID, keyFunc); err != nil {
+			file.Close()
+			return nil, err
+		}
+		f.bufWriter = bufio.NewWriterSize(f.enc, size)
+	} else {
+		f.bufWriter = bufio.NewWriterSize(file, size)
+	}
+	if w.audit {
+		// link to the last segment of the file appended, or the file before rotation
+		prev := w.auditPrev[level]
+		if w.encryptKeyID == "" {
+			if last := lastAuditChain(file); last != "" {
+				prev = last
+			}
+		}
+		var header string
+		if f.audit, header, err = newAuditChain(w.auditKey, prev); err == nil {
+			_, err = f.bufWriter.WriteString(header)
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if w.files == nil {
+		w.files = make(map[int]*logFile)
+	}
+	w.files[level] = f
+	w.suffix = filepath.Ext(filePath)
+	w.filenameOnly = strings.TrimSuffix(filePath, w.suffix)
+
+	if len(w.symlink) > 0 {
+		if w.shared {
+			if err = lockFile(file); err == nil {
+				defer unlockFile(file)
+			}
+		}
+		if err = linkFile(w.symlink.format(w.lastWriteTime, level), filePath); err != nil {
+			log.Printf("[go-log] file writer symlink err: %v", err.Error())
+		}
+	}
+	return f, nil
+}
+
+// linkFile point the symlink to file atomically, by renaming a new symlink to it,
+// the target is relative to the symlink, so the dir can be moved
+func linkFile(link, file string) error {
+	target, err := filepath.Rel(filepath.Dir(link), file)
+	if err != nil {
+		if target, err = filepath.Abs(file); err != nil {
+			return err
+		}
+	}
+	tmp := link + ".tmp" + strconv.Itoa(os.Getpid())
+	_ = os.Remove(tmp)
+	if err = os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// closeFiles flush and close all opened files
+func (w *FileWriter) closeFiles() error {
+	var err error
+	for level, f := range w.files {
+		if e := f.close(w.syncMode != SyncNone && w.syncMode != ""); e != nil && err == nil {
+			err = e
+		}
+		if f.audit != nil {
+			if w.auditPrev == nil {
+				w.auditPrev = make(map[int]string)
+			}
+			w.auditPrev[level] = f.audit.last()
+		}
+		delete(w.files, level)
 	}
-	_, err := w.fileBufWriter.WriteString(r.String())
 	return err
 }
 
@@ -164,55 +442,77 @@ func (w *FileWriter) Init() error {
 		if w.minutely && w.maxMinutes <= 0 {
 			w.maxMinutes = 1
 		}
+		if w.secondly && w.maxSeconds <= 0 {
+			w.maxSeconds = 1
+		}
 	}
 
 	return w.Rotate()
 }
 
-// Flush writes any buffered data to file
+// Flush writes any buffered data to file, and fsync files in SyncInterval mode if the interval passed
 func (w *FileWriter) Flush() error {
-	if w.fileBufWriter != nil {
-		return w.fileBufWriter.Flush()
+	var err error
+	for _, f := range w.files {
+		if e := f.flush(); e != nil && err == nil {
+			err = e
+		}
 	}
-	return nil
-}
-
-// SetPathPattern for file writer
-func (w *FileWriter) SetPathPattern(pattern string) error {
-	n := 0
-	for _, c := range pattern {
-		if c == '%' {
-			n++
+	if w.syncMode == SyncInterval {
+		if e := w.syncOnInterval(); e != nil && err == nil {
+			err = e
 		}
 	}
+	return err
+}
 
-	if n == 0 {
-		w.pathFmt = pattern
-		return nil
-	}
+// Close flush buffered data and close the opened files
+func (w *FileWriter) Close() error {
+	return w.closeFiles()
+}
 
-	w.actions = make([]func(*time.Time) int, 0, n)
-	w.variables = make([]interface{}, n, n)
-	tmp := []byte(pattern)
+// SetLevel file writer write records with level up to lvl
+func (w *FileWriter) SetLevel(lvl int) {
+	w.levels = LevelsUpTo(lvl)
+}
 
-	variable := 0
-	for _, c := range tmp {
-		if variable == 1 {
-			act, ok := pathVariableTable[c]
-			if !ok {
-				return errors.New("invalid rotate pattern (" + pattern + ")")
-			}
-			w.actions = append(w.actions, act)
-			variable = 0
-			continue
-		}
-		if c == '%' {
-			variable = 1
-		}
-	}
+// SetLevels file writer write records with level in the set only
+func (w *FileWriter) SetLevels(levels LevelSet) {
+	w.levels = levels
+}
+
+// Levels levels accepted by file writer
+func (w *FileWriter) Levels() LevelSet {
+	return w.levels
+}
 
-	w.pathFmt = convertPatternToFmt(tmp)
+// SetClock set the clock used by rotation, should call before Init
+func (w *FileWriter) SetClock(c Clock) {
+	w.clock = c
+}
+
+// SetLocation set the location of rotation and path variables, should call before Init
+func (w *FileWriter) SetLocation(loc *time.Location) {
+	w.location = loc
+}
 
+// SetPathPattern for file writer
+func (w *FileWriter) SetPathPattern(pattern string) error {
+	p, err := parsePathPattern(pattern)
+	if err != nil {
+		return err
+	}
+	w.pattern = p
+	return nil
+}
+
+// SetSymlink set the symlink to the current file, "" for no symlink, should call before Init
+func (w *FileWriter) SetSymlink(pattern string) error {
+	p, err := parsePathPattern(pattern)
+	if err != nil {
+		return err
+	}
+	w.symlink = p
 	return nil
 }
 
@@ -224,127 +524,66 @@ func (w *FileWriter) initFile() {
 
 // Rotate file writer rotate
 func (w *FileWriter) Rotate() error {
-	now := time.Now()
-	v := 0
-	rotate := false
-	for i, act := range w.actions {
-		v = act(&now)
-		if v != w.variables[i] {
-			if !w.initFileOk {
-				w.variables[i] = v
-				rotate = true
-			} else {
-				// only exec except the first round
-				switch i {
-				case 2:
-					if w.daily {
-						w.dailyOpenDate = v
-						w.dailyOpenTime = now
-						_, _, d := w.lastWriteTime.AddDate(0, 0, w.maxDays).Date()
-						if v == d {
-							rotate = true
-							w.variables[i] = v
-						}
-					}
-				case 3:
-					if w.hourly {
-						w.hourlyOpenDate = v
-						w.hourlyOpenTime = now
-						h := w.lastWriteTime.Add(time.Hour * time.Duration(w.maxHours)).Hour()
-						if v == h {
-							rotate = true
-							w.variables[i] = v
-						}
-					}
-				case 4:
-					if w.minutely {
-						w.minutelyOpenDate = v
-						w.minutelyOpenTime = now
-						m := w.lastWriteTime.Add(time.Minute * time.Duration(w.maxMinutes)).Minute()
-						if v == m {
-							rotate = true
-							w.variables[i] = v
-						}
-					}
-				}
-			}
-		}
+	now := clockOrSystem(w.clock).Now()
+	if w.location != nil {
+		now = now.In(w.location)
 	}
-	// must init file first!
-	if rotate == false {
+	if w.shared {
+		return w.sharedRotate(now)
+	}
+	// only exec except the first round
+	if w.initFileOk && !w.rotateDue(w.lastWriteTime, now) {
 		return nil
 	}
-	w.initFileOnce.Do(w.initFile)
-	w.lastWriteTime = now
+	return w.rotateAt(now)
+}
 
-	if w.fileBufWriter != nil {
-		if err := w.fileBufWriter.Flush(); err != nil {
-			return err
-		}
+// rotateDue report files opened at last should rotate at now
+func (w *FileWriter) rotateDue(last, now time.Time) bool {
+	units := w.pattern.changedUnits(last, now)
+	if units[unitDay] && w.daily &&
+		!now.Before(startOfDay(last).AddDate(0, 0, w.maxDays)) {
+		return true
 	}
-
-	if w.file != nil {
-		if err := w.file.Close(); err != nil {
-			return err
-		}
+	if units[unitHour] && w.hourly &&
+		!now.Before(startOfHour(last).Add(time.Hour*time.Duration(w.maxHours))) {
+		return true
 	}
+	if units[unitMinute] && w.minutely &&
+		!now.Before(startOfMinute(last).Add(time.Minute*time.Duration(w.maxMinutes))) {
+		return true
+	}
+	if units[unitSecond] && w.secondly &&
+		!now.Before(startOfSecond(last).Add(time.Second*time.Duration(w.maxSeconds))) {
+		return true
+	}
+	return false
+}
 
-	filePath := fmt.Sprintf(w.pathFmt, w.variables...)
-
-	if err := os.MkdirAll(path.Dir(filePath), w.rotatePerm); err != nil {
-		if !os.IsExist(err) {
-			return err
-		}
+// rotateAt close the opened files, and open files of time t
+func (w *FileWriter) rotateAt(t time.Time) error {
+	// must init file first!
+	if w.initFileOk {
+		atomic.AddUint64(&w.rotations, 1)
 	}
+	w.initFileOnce.Do(w.initFile)
+	w.lastWriteTime = t
 
-	if file, err := os.OpenFile(filePath, os.O_RDWR|os.O_CREATE|os.O_APPEND, w.rotatePerm); err == nil {
-		w.file = file
-	} else {
+	if err := w.closeFiles(); err != nil {
 		return err
 	}
-
-	if w.fileBufWriter = bufio.NewWriterSize(w.file, 8192); w.fileBufWriter == nil {
-		return errors.New("fileWriter new fileBufWriter failed")
+	// files by level are opened on the first record of level
+	if len(w.pattern) == 0 || w.pattern.hasLevel() {
+		return nil
 	}
-	w.suffix = filepath.Ext(filePath)
-	w.filenameOnly = strings.TrimSuffix(filePath, w.suffix)
-	return nil
-}
-
-func getYear(now *time.Time) int {
-	return now.Year()
-}
-
-func getMonth(now *time.Time) int {
-	return int(now.Month())
-}
-
-func getDay(now *time.Time) int {
-	return now.Day()
-}
-
-func getHour(now *time.Time) int {
-	return now.Hour()
-}
-
-func getMin(now *time.Time) int {
-	return now.Minute()
-}
-
-func convertPatternToFmt(pattern []byte) string {
-	pattern = bytes.Replace(pattern, []byte("%Y"), []byte("%d"), -1)
-	pattern = bytes.Replace(pattern, []byte("%M"), []byte("%02d"), -1)
-	pattern = bytes.Replace(pattern, []byte("%D"), []byte("%02d"), -1)
-	pattern = bytes.Replace(pattern, []byte("%H"), []byte("%02d"), -1)
-	pattern = bytes.Replace(pattern, []byte("%m"), []byte("%02d"), -1)
-	return string(pattern)
+	_, err := w.openFile(anyLevel)
+	return err
 }
 
-func init() {
-	pathVariableTable = make(map[byte]func(*time.Time) int, 5)
-	pathVariableTable['Y'] = getYear
-	pathVariableTable['M'] = getMonth
-	pathVariableTable['D'] = getDay
-	pathVariableTable['H'] = getHour
-	pathVariableTable['m'] = getMin
+// Stats statistics of file writer
+func (w *FileWriter) Stats() WriterStats {
+	return WriterStats{
+		BytesWritten: atomic.LoadUint64(&w.bytesWritten),
+		Rotations:    atomic.LoadUint64(&w.rotations),
+	}
 }
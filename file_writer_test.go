@@ -263,7 +263,7 @@ func Test_NewFileWriterWithNilFileBufWriter(t *testing.T) {
 		t.Error(err)
 	}
 	var name = "file color"
-	w.fileBufWriter = nil
+	w.files = nil
 	generateRegisterFileWriter(loggerDefaultTest, w, fullPath, funcName, layout)
 	curFilename := fmt.Sprintf("%s%s", w.filenameOnly, w.suffix)
 	defer deleteGenerateLogFile(curFilename)
@@ -0,0 +1,267 @@
+// Package gologhttp logs net/http requests by golog.
+package gologhttp
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	golog "github.com/legofun/go-log"
+)
+
+// access log formats
+const (
+	FormatCommon   = "common"   // Common Log Format
+	FormatCombined = "combined" // Combined Log Format, Common Log Format with referer and user agent
+	FormatFields   = "fields"   // message "METHOD path status" with structured fields
+)
+
+// clfTimeLayout time layout of Common Log Format
+const clfTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// AccessOptions access log middleware options
+type AccessOptions struct {
+	// Logger log requests at ACCESS level, the default logger if nil
+	Logger *golog.Logger
+	// Format of access log, FormatCombined by default
+	Format string
+	// TrustedProxies IPs or CIDRs of proxies, X-Forwarded-For is honored for requests from them
+	TrustedProxies []string
+	// SkipPaths requests of paths are not logged, a path ends with "*" matches the prefix
+	SkipPaths []string
+	// SkipStatuses responses of statuses are not logged
+	SkipStatuses []int
+	// Skip requests are not logged if it returns true
+	Skip func(r *http.Request, status int) bool
+	// Clock time source of request time and latency, golog.SystemClock if nil
+	Clock golog.Clock
+}
+
+// accessHandler handler logs requests
+type accessHandler struct {
+	next    http.Handler
+	options AccessOptions
+	proxies []*net.IPNet
+	clock   golog.Clock
+}
+
+// AccessHandler wrap the handler to log every request at ACCESS level
+func AccessHandler(next http.Handler, options AccessOptions) http.Handler {
+	h := &accessHandler{next: next, options: options, clock: options.Clock}
+	if h.clock == nil {
+		h.clock = golog.SystemClock
+	}
+	switch options.Format {
+	case "":
+		h.options.Format = FormatCombined
+	case FormatCommon, FormatCombined, FormatFields:
+	default:
+		log.Printf("[go-log] access log format err: invalid format (%s)", options.Format)
+		h.options.Format = FormatCombined
+	}
+	for _, p := range options.TrustedProxies {
+		n, err := parseIPNet(p)
+		if err != nil {
+			log.Printf("[go-log] access log trusted proxy err: %v", err.Error())
+			continue
+		}
+		h.proxies = append(h.proxies, n)
+	}
+	return h
+}
+
+// AccessMiddleware middleware of AccessHandler
+func AccessMiddleware(options AccessOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return AccessHandler(next, options)
+	}
+}
+
+// parseIPNet parse IP or CIDR
+func parseIPNet(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, errors.New("invalid IP (" + s + ")")
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+func (h *accessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := h.clock.Now()
+	rw := &responseWriter{ResponseWriter: w}
+	h.next.ServeHTTP(rw, r)
+
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if h.skip(r, status) {
+		return
+	}
+	latency := h.clock.Now().Sub(start)
+	remote := h.remoteAddr(r)
+
+	var fields []golog.Field
+	var msg string
+	switch h.options.Format {
+	case FormatFields:
+		msg = r.Method + " " + r.URL.Path + " " + strconv.Itoa(status)
+		fields = []golog.Field{
+			{Key: "method", Value: r.Method},
+			{Key: "path", Value: r.URL.Path},
+			{Key: "status", Value: status},
+			{Key: "bytes", Value: rw.bytes},
+			{Key: "latency", Value: latency},
+			{Key: "remote_addr", Value: remote},
+			{Key: "user_agent", Value: r.UserAgent()},
+		}
+	default:
+		msg = h.commonLog(r, remote, start, status, rw.bytes)
+	}
+	if h.options.Logger != nil {
+		h.options.Logger.LogFields(golog.ACCESS, fields, "%s", msg)
+	} else {
+		golog.LogFields(golog.ACCESS, fields, "%s", msg)
+	}
+}
+
+// skip report the request should not be logged
+func (h *accessHandler) skip(r *http.Request, status int) bool {
+	for _, p := range h.options.SkipPaths {
+		if p == r.URL.Path || strings.HasSuffix(p, "*") && strings.HasPrefix(r.URL.Path, p[:len(p)-1]) {
+			return true
+		}
+	}
+	for _, s := range h.options.SkipStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return h.options.Skip != nil && h.options.Skip(r, status)
+}
+
+// commonLog format the request in Common or Combined Log Format
+func (h *accessHandler) commonLog(r *http.Request, remote string, start time.Time, status int, bytes int64) string {
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	} else if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	}
+	size := "-"
+	if bytes > 0 {
+		size = strconv.FormatInt(bytes, 10)
+	}
+	var b strings.Builder
+	b.WriteString(remote + " - " + user + " [" + start.Format(clfTimeLayout) + "] ")
+	b.WriteString(strconv.Quote(r.Method + " " + r.URL.RequestURI() + " " + r.Proto))
+	b.WriteString(" " + strconv.Itoa(status) + " " + size)
+	if h.options.Format == FormatCombined {
+		b.WriteString(" " + strconv.Quote(r.Referer()) + " " + strconv.Quote(r.UserAgent()))
+	}
+	return b.String()
+}
+
+// remoteAddr client address, from X-Forwarded-For if the request is from trusted proxies,
+// the rightmost untrusted address is the client, others may be forged
+func (h *accessHandler) remoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !h.trusted(host) {
+		return host
+	}
+	var addrs []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				addrs = append(addrs, a)
+			}
+		}
+	}
+	for i := len(addrs) - 1; i >= 0; i-- {
+		host = addrs[i]
+		if !h.trusted(host) {
+			break
+		}
+	}
+	return host
+}
+
+// trusted report the address is of trusted proxies
+func (h *accessHandler) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range h.proxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseWriter captures status and bytes of the response
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush flush the response if supported
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack hijack the connection if supported, the status is 101 Switching Protocols
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijack")
+	}
+	if w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return h.Hijack()
+}
+
+// Unwrap return the wrapped response writer, for http.ResponseController
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
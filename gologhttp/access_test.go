@@ -0,0 +1,111 @@
+package gologhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+func serve(h http.Handler, target string, header http.Header) {
+	r := httptest.NewRequest("GET", target, nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
+
+func Test_AccessHandlerCombined(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	clock := gologtest.NewFakeClock(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	h := AccessHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}), AccessOptions{Logger: l, Clock: clock})
+
+	serve(h, "/users?id=1", http.Header{"User-Agent": {"curl/8"}, "Referer": {"http://a/"}})
+
+	records := o.FilterLevel(golog.ACCESS)
+	if len(records) != 1 {
+		t.Fatalf("got %d access records, want 1", len(records))
+	}
+	gologtest.AssertMessage(t, records[0],
+		`10.0.0.1 - - [02/Jan/2023:03:04:05 +0000] "GET /users?id=1 HTTP/1.1" 201 5 "http://a/" "curl/8"`)
+}
+
+func Test_AccessHandlerFields(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	clock := gologtest.NewFakeClock(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))
+	h := AccessHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clock.Add(15 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}), AccessOptions{Logger: l, Clock: clock, Format: FormatFields, TrustedProxies: []string{"10.0.0.0/8"}})
+
+	serve(h, "/orders", http.Header{"X-Forwarded-For": {"1.1.1.1, 2.2.2.2", "10.0.0.2"}, "User-Agent": {"curl/8"}})
+
+	records := o.Records()
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	r := records[0]
+	gologtest.AssertMessage(t, r, "GET /orders 200")
+	gologtest.AssertField(t, r, "method", "GET")
+	gologtest.AssertField(t, r, "path", "/orders")
+	gologtest.AssertField(t, r, "status", 200)
+	gologtest.AssertField(t, r, "bytes", 2)
+	gologtest.AssertField(t, r, "latency", 15*time.Millisecond)
+	gologtest.AssertField(t, r, "remote_addr", "2.2.2.2")
+	gologtest.AssertField(t, r, "user_agent", "curl/8")
+}
+
+func Test_AccessHandlerRemoteAddr(t *testing.T) {
+	h := AccessHandler(nil, AccessOptions{TrustedProxies: []string{"10.0.0.1", "bad"}}).(*accessHandler)
+	tests := []struct {
+		remote string
+		xff    string
+		want   string
+	}{
+		{"10.0.0.1:80", "1.1.1.1", "1.1.1.1"},
+		{"10.0.0.9:80", "1.1.1.1", "10.0.0.9"},
+		{"10.0.0.1:80", "", "10.0.0.1"},
+		{"10.0.0.1:80", "10.0.0.1", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		if tt.xff != "" {
+			r.Header.Set("X-Forwarded-For", tt.xff)
+		}
+		if got := h.remoteAddr(r); got != tt.want {
+			t.Errorf("remote addr of %s %s got %s, want %s", tt.remote, tt.xff, got, tt.want)
+		}
+	}
+}
+
+func Test_AccessHandlerSkip(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	h := AccessHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), AccessOptions{
+		Logger:       l,
+		Format:       FormatCommon,
+		SkipPaths:    []string{"/healthz", "/static/*"},
+		SkipStatuses: []int{http.StatusNotFound},
+		Skip: func(r *http.Request, status int) bool {
+			return r.Method == http.MethodGet && r.URL.Path == "/skip"
+		},
+	})
+	for _, p := range []string{"/healthz", "/static/app.js", "/missing", "/skip", "/logged"} {
+		serve(h, p, nil)
+	}
+	if o.Len() != 1 {
+		t.Fatalf("got %d records, want 1", o.Len())
+	}
+	o.AssertLogged(t, golog.ACCESS, `"GET /logged HTTP/1.1" 200 -`)
+}
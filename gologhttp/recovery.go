@@ -0,0 +1,62 @@
+package gologhttp
+
+import (
+	"net/http"
+
+	golog "github.com/legofun/go-log"
+)
+
+// RecoveryOptions recovery middleware options
+type RecoveryOptions struct {
+	// Logger log panics at ERROR level, the default logger if nil
+	Logger *golog.Logger
+}
+
+// recoveryHandler handler recovers panics
+type recoveryHandler struct {
+	next    http.Handler
+	options RecoveryOptions
+}
+
+// RecoveryHandler wrap the handler to recover panics, a panic is logged at ERROR level
+// with the stack trace and request, then 500 Internal Server Error is returned if the response
+// is not written yet, otherwise http.ErrAbortHandler is panicked to abort the truncated response
+func RecoveryHandler(next http.Handler, options RecoveryOptions) http.Handler {
+	return &recoveryHandler{next: next, options: options}
+}
+
+// RecoveryMiddleware middleware of RecoveryHandler
+func RecoveryMiddleware(options RecoveryOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return RecoveryHandler(next, options)
+	}
+}
+
+func (h *recoveryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := &responseWriter{ResponseWriter: w}
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		if v == http.ErrAbortHandler {
+			panic(v)
+		}
+		fields := []golog.Field{
+			{Key: "method", Value: r.Method},
+			{Key: "uri", Value: r.RequestURI},
+			{Key: "remote_addr", Value: r.RemoteAddr},
+			{Key: "user_agent", Value: r.UserAgent()},
+		}
+		if h.options.Logger != nil {
+			h.options.Logger.LogPanic(v, fields...)
+		} else {
+			golog.LogPanic(v, fields...)
+		}
+		if rw.status != 0 {
+			panic(http.ErrAbortHandler)
+		}
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}()
+	h.next.ServeHTTP(rw, r)
+}
@@ -0,0 +1,90 @@
+package gologhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+func Test_RecoveryHandler(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	h := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}), RecoveryOptions{Logger: l})
+
+	r := httptest.NewRequest("POST", "/orders?id=1", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status got %d, want 500", w.Code)
+	}
+	records := o.FilterLevel(golog.ERROR)
+	if len(records) != 1 {
+		t.Fatalf("got %d error records, want 1", len(records))
+	}
+	gologtest.AssertMessageContains(t, records[0], "panic: boom\n")
+	gologtest.AssertMessageContains(t, records[0], "recovery_test.go")
+	gologtest.AssertField(t, records[0], "method", "POST")
+	gologtest.AssertField(t, records[0], "uri", "/orders?id=1")
+}
+
+func Test_RecoveryHandlerWritten(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	h := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("after write")
+	}), RecoveryOptions{Logger: l})
+
+	w := httptest.NewRecorder()
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recover got %v, want %v", v, http.ErrAbortHandler)
+		}
+		if w.Code != http.StatusAccepted {
+			t.Errorf("status got %d, want 202", w.Code)
+		}
+		o.AssertLogged(t, golog.ERROR, "panic: after write")
+	}()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+}
+
+func Test_RecoveryHandlerWrittenServer(t *testing.T) {
+	l, _ := gologtest.NewLogger(t)
+	server := httptest.NewServer(RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("part"))
+		w.(http.Flusher).Flush()
+		panic("after write")
+	}), RecoveryOptions{Logger: l}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Error("truncated response read without error")
+	}
+}
+
+func Test_RecoveryHandlerAbort(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	h := RecoveryHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), RecoveryOptions{Logger: l})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("recover got %v, want %v", v, http.ErrAbortHandler)
+		}
+		if o.Len() != 0 {
+			t.Errorf("abort should not be logged")
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
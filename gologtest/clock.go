@@ -0,0 +1,39 @@
+package gologtest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock clock only changes when set or advanced, implements golog.Clock
+type FakeClock struct {
+	lock sync.RWMutex
+	now  time.Time
+}
+
+// NewFakeClock create fake clock at the time
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now return the current time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.now
+}
+
+// Set set the current time of the clock
+func (c *FakeClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = now
+}
+
+// Add advance the clock by d, return the new current time
+func (c *FakeClock) Add(d time.Duration) time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
@@ -0,0 +1,99 @@
+// Package gologtest helps to capture and assert on golog records in tests.
+package gologtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	golog "github.com/legofun/go-log"
+)
+
+// Observer writer collects copies of records for assertions
+type Observer struct {
+	lock    sync.Mutex
+	records []golog.Record
+	logger  *golog.Logger // synced before reading records if set
+}
+
+// NewObserver create observer
+func NewObserver() *Observer {
+	return &Observer{}
+}
+
+// Init observer init without implement
+func (o *Observer) Init() error {
+	return nil
+}
+
+// Write collect a copy of the record, the record itself is reused by logger
+func (o *Observer) Write(r *golog.Record) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.records = append(o.records, *r)
+	return nil
+}
+
+// Records return the records collected,
+// with logger set, records logged before the call are all collected
+func (o *Observer) Records() []golog.Record {
+	if o.logger != nil {
+		o.logger.Sync()
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	records := make([]golog.Record, len(o.records))
+	copy(records, o.records)
+	return records
+}
+
+// Len return the count of records collected
+func (o *Observer) Len() int {
+	return len(o.Records())
+}
+
+// Reset drop the records collected
+func (o *Observer) Reset() {
+	if o.logger != nil {
+		o.logger.Sync()
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.records = nil
+}
+
+// Filter return records match the predicate
+func (o *Observer) Filter(match func(*golog.Record) bool) []golog.Record {
+	records := o.Records()
+	matched := records[:0]
+	for i := range records {
+		if match(&records[i]) {
+			matched = append(matched, records[i])
+		}
+	}
+	return matched
+}
+
+// FilterLevel return records of the levels
+func (o *Observer) FilterLevel(levels ...int) []golog.Record {
+	s := golog.NewLevelSet(levels...)
+	return o.Filter(func(r *golog.Record) bool {
+		return s.Contains(r.Level())
+	})
+}
+
+// FilterMessage return records with message contains substr
+func (o *Observer) FilterMessage(substr string) []golog.Record {
+	return o.Filter(func(r *golog.Record) bool {
+		return strings.Contains(r.Msg(), substr)
+	})
+}
+
+// FilterField return records with the field, values are compared by their formatted text
+func (o *Observer) FilterField(key string, value interface{}) []golog.Record {
+	want := fmt.Sprint(value)
+	return o.Filter(func(r *golog.Record) bool {
+		v, ok := r.Field(key)
+		return ok && fmt.Sprint(v) == want
+	})
+}
@@ -0,0 +1,82 @@
+package gologtest
+
+import (
+	"strings"
+	"testing"
+
+	golog "github.com/legofun/go-log"
+)
+
+func Test_Observer(t *testing.T) {
+	l, o := NewLogger(t)
+	l.SetLevel(golog.COMMON)
+
+	l.Debug("dropped by logger level")
+	l.Common("common %d", 1)
+	l.Error("error %s", "two")
+
+	records := o.Records()
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	AssertLevel(t, records[0], golog.COMMON)
+	AssertMessage(t, records[0], "common 1")
+	AssertCaller(t, records[1], "observer_test.go:16")
+	AssertMessageContains(t, records[1], "two")
+
+	if errs := o.FilterLevel(golog.ERROR, golog.ACCESS); len(errs) != 1 || errs[0].Msg() != "error two" {
+		t.Errorf("filter level got %v", errs)
+	}
+	if got := o.FilterMessage("common"); len(got) != 1 {
+		t.Errorf("filter message got %v", got)
+	}
+	o.AssertLogged(t, golog.ERROR, "two")
+	o.AssertNotLogged(t, golog.DEBUG, "dropped")
+
+	o.Reset()
+	l.Access("after reset")
+	if o.Len() != 1 {
+		t.Errorf("got %d records after reset, want 1", o.Len())
+	}
+}
+
+func Test_ObserverAssertFailure(t *testing.T) {
+	l, o := NewLogger(t)
+	l.Transaction("txn")
+
+	ft := &fakeT{TB: t}
+	r := o.Records()[0]
+	AssertLevel(ft, r, golog.ERROR)
+	AssertMessage(ft, r, "other")
+	AssertCaller(ft, r, "main.go")
+	o.AssertLogged(ft, golog.TRANSACTION, "missing")
+	o.AssertNotLogged(ft, golog.TRANSACTION, "txn")
+	if ft.errors != 5 {
+		t.Errorf("got %d assert failures, want 5", ft.errors)
+	}
+}
+
+type fakeT struct {
+	testing.TB
+	errors int
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors++
+}
+
+func Test_ObserverFields(t *testing.T) {
+	l, o := NewLogger(t)
+	l.LogFields(golog.ACCESS, []golog.Field{{Key: "status", Value: 200}, {Key: "path", Value: "/a b"}}, "GET")
+
+	records := o.FilterField("status", 200)
+	if len(records) != 1 {
+		t.Fatalf("got %d records with field, want 1", len(records))
+	}
+	AssertField(t, records[0], "path", "/a b")
+	AssertNoField(t, records[0], "bytes")
+	AssertCaller(t, records[0], "observer_test.go:70")
+	if s := records[0].String(); !strings.HasSuffix(s, `> GET status=200 path="/a b"`+"\n") {
+		t.Errorf("record string got %q", s)
+	}
+}
@@ -0,0 +1,119 @@
+package gologtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	golog "github.com/legofun/go-log"
+)
+
+// TestingWriter writer output records by testing.TB Log,
+// so the output is attached to the test
+type TestingWriter struct {
+	t testing.TB
+}
+
+// NewTestingWriter create testing writer
+func NewTestingWriter(t testing.TB) *TestingWriter {
+	return &TestingWriter{t: t}
+}
+
+// Init testing writer init without implement
+func (w *TestingWriter) Init() error {
+	return nil
+}
+
+// Write log record by testing.TB Log
+func (w *TestingWriter) Write(r *golog.Record) error {
+	w.t.Log(strings.TrimSuffix(r.String(), "\n"))
+	return nil
+}
+
+// NewLogger create a new logger with an observer and a testing writer,
+// the logger is closed when the test and its subtests complete
+func NewLogger(t testing.TB) (*golog.Logger, *Observer) {
+	l := golog.NewLoggerWithChanSize(0)
+	o := NewObserver()
+	o.logger = l
+	l.Register(o)
+	l.Register(NewTestingWriter(t))
+	t.Cleanup(l.Close)
+	return l, o
+}
+
+// AssertLevel assert the level of record
+func AssertLevel(t testing.TB, r golog.Record, level int) {
+	t.Helper()
+	if r.Level() != level {
+		t.Errorf("record level got %s, want %s", golog.LevelFlags[r.Level()], golog.LevelFlags[level])
+	}
+}
+
+// AssertMessage assert the message of record
+func AssertMessage(t testing.TB, r golog.Record, msg string) {
+	t.Helper()
+	if r.Msg() != msg {
+		t.Errorf("record message got %q, want %q", r.Msg(), msg)
+	}
+}
+
+// AssertMessageContains assert the message of record contains substr
+func AssertMessageContains(t testing.TB, r golog.Record, substr string) {
+	t.Helper()
+	if !strings.Contains(r.Msg(), substr) {
+		t.Errorf("record message %q should contain %q", r.Msg(), substr)
+	}
+}
+
+// AssertCaller assert the caller of record, file is like "main.go" or "main.go:12"
+func AssertCaller(t testing.TB, r golog.Record, file string) {
+	t.Helper()
+	caller := r.File()
+	if !strings.HasPrefix(caller, file) && !strings.Contains(caller, "/"+file) {
+		t.Errorf("record caller got %q, want %q", caller, file)
+	}
+}
+
+// AssertField assert the field of record, values are compared by their formatted text
+func AssertField(t testing.TB, r golog.Record, key string, value interface{}) {
+	t.Helper()
+	v, ok := r.Field(key)
+	if !ok {
+		t.Errorf("record has no field %q", key)
+		return
+	}
+	if fmt.Sprint(v) != fmt.Sprint(value) {
+		t.Errorf("record field %s got %v, want %v", key, v, value)
+	}
+}
+
+// AssertNoField assert the record has no field of key
+func AssertNoField(t testing.TB, r golog.Record, key string) {
+	t.Helper()
+	if v, ok := r.Field(key); ok {
+		t.Errorf("unexpected record field %s=%v", key, v)
+	}
+}
+
+// AssertLogged assert some record of the level with message contains substr is collected
+func (o *Observer) AssertLogged(t testing.TB, level int, substr string) {
+	t.Helper()
+	for _, r := range o.FilterLevel(level) {
+		if strings.Contains(r.Msg(), substr) {
+			return
+		}
+	}
+	t.Errorf("no %s record with message contains %q", golog.LevelFlags[level], substr)
+}
+
+// AssertNotLogged assert no record of the level with message contains substr is collected
+func (o *Observer) AssertNotLogged(t testing.TB, level int, substr string) {
+	t.Helper()
+	for _, r := range o.FilterLevel(level) {
+		if strings.Contains(r.Msg(), substr) {
+			t.Errorf("unexpected %s record %q", golog.LevelFlags[level], r.Msg())
+			return
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package golog
+
+// Hook callback of records, called by the goroutine logs the record before the record is sent to writers,
+// so hooks may be called concurrently and can log. Should return fast,
+// the record is reused after the call so it must not be kept
+type Hook func(r *Record)
+
+// hook registered hook with levels
+type hook struct {
+	levels LevelSet
+	fn     Hook
+}
+
+// AddHook register hook called for records of levels
+func (l *Logger) AddHook(levels LevelSet, h Hook) {
+	l.hooksLock.Lock()
+	defer l.hooksLock.Unlock()
+	l.hooks = append(l.hooks, hook{levels: levels, fn: h})
+}
+
+// runHooks call hooks of the record level
+func (l *Logger) runHooks(r *Record) {
+	l.hooksLock.RLock()
+	defer l.hooksLock.RUnlock()
+	for _, h := range l.hooks {
+		if h.levels.Contains(r.level) {
+			h.fn(r)
+		}
+	}
+}
+
+// AddHook register hook of the default logger
+func AddHook(levels LevelSet, h Hook) {
+	loggerDefault.AddHook(levels, h)
+}
@@ -0,0 +1,110 @@
+package golog
+
+import (
+	"errors"
+	"strings"
+)
+
+// LevelSet set of levels a writer accepts, bit n stands for level n
+type LevelSet uint8
+
+// LevelSetAll accept all levels
+const LevelSetAll = LevelSet(1<<(DEBUG+1) - 1)
+
+// levelRangeSep separator of a level range flag, like "error-common"
+const levelRangeSep = "-"
+
+// NewLevelSet create level set with the given levels
+func NewLevelSet(levels ...int) LevelSet {
+	var s LevelSet
+	for _, lvl := range levels {
+		if lvl < ACCESS || lvl > DEBUG {
+			continue
+		}
+		s |= 1 << uint(lvl)
+	}
+	return s
+}
+
+// LevelRange create level set with all levels between from and to, both included
+func LevelRange(from, to int) LevelSet {
+	if from > to {
+		from, to = to, from
+	}
+	var s LevelSet
+	for lvl := from; lvl <= to; lvl++ {
+		s |= NewLevelSet(lvl)
+	}
+	return s
+}
+
+// LevelsUpTo create level set same as the max level filter,
+// all levels from ACCESS to the given level
+func LevelsUpTo(level int) LevelSet {
+	return LevelRange(ACCESS, level)
+}
+
+// Contains report the level is in the set or not
+func (s LevelSet) Contains(level int) bool {
+	if level < ACCESS || level > DEBUG {
+		return false
+	}
+	return s&(1<<uint(level)) != 0
+}
+
+// Max return the max level in the set, -1 if the set is empty
+func (s LevelSet) Max() int {
+	for lvl := DEBUG; lvl >= ACCESS; lvl-- {
+		if s.Contains(lvl) {
+			return lvl
+		}
+	}
+	return -1
+}
+
+// String level flags joined by "|"
+func (s LevelSet) String() string {
+	flags := make([]string, 0, len(LevelFlags))
+	for lvl := ACCESS; lvl <= DEBUG; lvl++ {
+		if s.Contains(lvl) {
+			flags = append(flags, LevelFlags[lvl])
+		}
+	}
+	return strings.Join(flags, "|")
+}
+
+// ParseLevelSet parse level flags to level set,
+// each flag is a level like "access" or a range like "error-common"
+func ParseLevelSet(flags []string) (LevelSet, error) {
+	var s LevelSet
+	for _, flag := range flags {
+		if from, to, ok := strings.Cut(flag, levelRangeSep); ok {
+			fromLevel, err := parseLevel(from)
+			if err != nil {
+				return 0, err
+			}
+			toLevel, err := parseLevel(to)
+			if err != nil {
+				return 0, err
+			}
+			s |= LevelRange(fromLevel, toLevel)
+			continue
+		}
+		lvl, err := parseLevel(flag)
+		if err != nil {
+			return 0, err
+		}
+		s |= NewLevelSet(lvl)
+	}
+	return s, nil
+}
+
+// parseLevel parse level flag case-insensitively
+func parseLevel(flag string) (int, error) {
+	for lvl := ACCESS; lvl <= DEBUG; lvl++ {
+		if strings.TrimSpace(strings.ToUpper(flag)) == LevelFlags[lvl] {
+			return lvl, nil
+		}
+	}
+	return -1, errors.New("invalid level flag (" + flag + ")")
+}
@@ -0,0 +1,50 @@
+package golog
+
+import (
+	"testing"
+)
+
+func Test_LevelSet(t *testing.T) {
+	s := NewLevelSet(ACCESS, TRANSACTION)
+	if !s.Contains(ACCESS) || !s.Contains(TRANSACTION) || s.Contains(ERROR) {
+		t.Errorf("level set %v contains wrong levels", s)
+	}
+	if s.Max() != TRANSACTION {
+		t.Errorf("level set %v max %d, want %d", s, s.Max(), TRANSACTION)
+	}
+	if LevelSet(0).Max() != -1 {
+		t.Errorf("empty level set max should be -1")
+	}
+	if LevelsUpTo(DEBUG) != LevelSetAll {
+		t.Errorf("levels up to debug %v, want all", LevelsUpTo(DEBUG))
+	}
+	if got := LevelRange(COMMON, ERROR).String(); got != "ERROR|TRANSACTION|ABNORMAL|COMMON" {
+		t.Errorf("level range string %s", got)
+	}
+}
+
+func Test_ParseLevelSet(t *testing.T) {
+	cases := []struct {
+		flags []string
+		want  LevelSet
+		err   bool
+	}{
+		{[]string{"access"}, NewLevelSet(ACCESS), false},
+		{[]string{"Transaction", " debug "}, NewLevelSet(TRANSACTION, DEBUG), false},
+		{[]string{"error-debug"}, LevelRange(ERROR, DEBUG), false},
+		{[]string{"common-error"}, LevelRange(ERROR, COMMON), false},
+		{[]string{"access", "abnormal-common"}, NewLevelSet(ACCESS, ABNORMAL, COMMON), false},
+		{[]string{"warn"}, 0, true},
+		{[]string{"error-warn"}, 0, true},
+	}
+	for _, c := range cases {
+		got, err := ParseLevelSet(c.flags)
+		if (err != nil) != c.err {
+			t.Errorf("parse %v err %v, want err %v", c.flags, err, c.err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("parse %v got %v, want %v", c.flags, got, c.want)
+		}
+	}
+}
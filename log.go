@@ -2,12 +2,15 @@ package golog
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log"
+	"os"
 	"path"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -36,21 +39,26 @@ const (
 	recordChannelSizeDefault = uint(4096)
 	// default time layout
 	defaultLayout = "2006/01/02 15:04:05"
-	// timestamp with zone info
-	timestampLayout = "2006-01-02T15:04:05.000+0800"
+	// timestamp with zone info, -0700 prints the real offset of the location
+	timestampLayout = "2006-01-02T15:04:05.000-0700"
+	// default timer to flush writers
+	flushTimerDefault = time.Millisecond * 500
+	// default timer to rotate writers
+	rotateTimerDefault = time.Second * 10
 )
 
-// LevelFlags level Flags set
+// LevelFlags level Flags set, indexed by level
 var (
 	LevelFlags = []string{
 		LevelFlagAccess,
-		LevelFlagTransaction,
 		LevelFlagError,
+		LevelFlagTransaction,
 		LevelFlagAbnormal,
 		LevelFlagCommon,
 		LevelFlagDebug,
 	}
-	DefaultLayout = defaultLayout
+	DefaultLayout   = defaultLayout
+	TimestampLayout = timestampLayout
 )
 
 // default logger
@@ -62,14 +70,56 @@ var (
 
 // Record log record
 type Record struct {
-	level int
-	time  string
-	file  string
-	msg   string
+	level     int
+	time      string
+	timestamp time.Time // raw time of record, for encoders
+	file      string
+	msg       string
+	format    string  // format of the message before args applied
+	fields    []Field // structured fields after the message
+
+	synced chan struct{} // not nil for the record sent by Sync
 }
 
 func (r *Record) String() string {
-	return fmt.Sprintf("%s [%s] <%s> %s\n", r.time, LevelFlags[r.level], r.file, r.msg)
+	if len(r.fields) == 0 {
+		return fmt.Sprintf("%s [%s] <%s> %s\n", r.time, LevelFlags[r.level], r.file, r.msg)
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s [%s] <%s> %s", r.time, LevelFlags[r.level], r.file, r.msg))
+	appendFields(&b, r.fields)
+	b.WriteByte('\n')
+	return b.String()
+}
+
+// Level level of the record
+func (r *Record) Level() int {
+	return r.level
+}
+
+// Time formatted time of the record
+func (r *Record) Time() string {
+	return r.time
+}
+
+// Timestamp raw time of the record, in the location of logger
+func (r *Record) Timestamp() time.Time {
+	return r.timestamp
+}
+
+// File caller file and line number of the record, with func name if enabled
+func (r *Record) File() string {
+	return r.file
+}
+
+// Msg formatted message of the record
+func (r *Record) Msg() string {
+	return r.msg
+}
+
+// Format format string of the message, before args are applied
+func (r *Record) Format() string {
+	return r.format
 }
 
 // Writer record writer
@@ -83,6 +133,16 @@ type Flusher interface {
 	Flush() error
 }
 
+// Leveler writer with levels filter, used to decide the logger level
+type Leveler interface {
+	Levels() LevelSet
+}
+
+// Closer record writer closer, release the resources like opened files
+type Closer interface {
+	Close() error
+}
+
 // Rotater record rotater
 type Rotater interface {
 	Rotate() error
@@ -92,18 +152,32 @@ type Rotater interface {
 // Logger logger define
 type Logger struct {
 	writers         []Writer
+	writersLock     sync.RWMutex       // writers may be replaced by config reload
+	configured      []configuredWriter // writers created by config, guarded by writersLock
+	configLock      sync.Mutex         // serializes applying config
+	registered      map[Writer]int     // sequence of writers registered by hand, for stable names
+	hooks           []hook
+	hooksLock       sync.RWMutex
+	metrics         *loggerMetrics
+	writerErrors    *writerErrors // error handler, policy and failures of writers
+	shutdown        *loggerShutdown
+	synchronous     *synchronousMode
 	records         chan *Record
 	recordsChanSize uint
 	lastTime        int64
-	lastTimeStr     string
+	lastTimeStr     string // time formatted by layoutPrefix of lastTime
 
 	flushTimer  time.Duration // timer to flush logger record to chan
 	rotateTimer time.Duration // timer to rotate logger record for writer
 
 	c chan bool
 
+	clock        Clock          // time source of records, SystemClock if nil
+	location     *time.Location // location of record time, the clock's location if nil
 	layout       string
-	level        int
+	layoutPrefix string // layout before fractional second, cached per second
+	layoutSuffix string // layout from fractional second, formatted per record
+	level        int32
 	fullPath     bool // show full path, default only show file:line_number
 	withFuncName bool // show caller func name
 	lock         sync.RWMutex
@@ -119,6 +193,19 @@ func NewLogger() *Logger {
 	return newLoggerWithRecords(records)
 }
 
+// NewLoggerWithChanSize create a new logger other than the default logger,
+// size is the size of records channel, default size is used if size is 0
+func NewLoggerWithChanSize(size uint) *Logger {
+	if size == 0 {
+		size = recordChannelSizeDefault
+	}
+	l := newLoggerWithRecords(make(chan *Record, size))
+	l.recordsChanSize = size
+	l.flushTimer = flushTimerDefault
+	l.rotateTimer = rotateTimerDefault
+	return l
+}
+
 // newLoggerWithRecords is useful for go test
 func newLoggerWithRecords(records chan *Record) *Logger {
 	l := new(Logger)
@@ -128,9 +215,13 @@ func newLoggerWithRecords(records chan *Record) *Logger {
 	}
 
 	l.records = records
+	l.metrics = new(loggerMetrics)
+	l.writerErrors = new(writerErrors)
+	l.shutdown = newLoggerShutdown()
+	l.synchronous = new(synchronousMode)
 	l.c = make(chan bool, 1)
 	l.level = DEBUG
-	l.layout = DefaultLayout
+	l.SetLayout(DefaultLayout)
 
 	go bootstrapLogWriter(l)
 
@@ -144,31 +235,61 @@ func (l *Logger) Register(w Writer) {
 		panic(err)
 	}
 
+	l.writersLock.Lock()
 	l.writers = append(l.writers, w)
+	if l.registered == nil {
+		l.registered = make(map[Writer]int)
+	}
+	if _, ok := l.registered[w]; !ok {
+		l.registered[w] = len(l.registered)
+	}
+	l.writersLock.Unlock()
 }
 
-// Close close logger
+// Close close logger, shut down it within 10s
 func (l *Logger) Close() {
-	close(l.records)
-	<-l.c
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeoutDefault)
+	defer cancel()
+	if err := l.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "[go-log] close err: %v\n", err)
+	}
+}
 
-	for _, w := range l.writers {
-		if f, ok := w.(Flusher); ok {
-			if err := f.Flush(); err != nil {
-				log.Println(err)
-			}
-		}
+// Sync block until the records logged before are written to writers, then flush writers,
+// return at once if the logger is shut down
+func (l *Logger) Sync() {
+	r := &Record{synced: make(chan struct{})}
+	if !l.enqueue(r) {
+		return
 	}
+	<-r.synced
 }
 
 // SetLayout set the logger time layout
 func (l *Logger) SetLayout(layout string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
 	l.layout = layout
+	l.layoutPrefix, l.layoutSuffix = splitLayout(layout)
+	l.lastTime = 0 // format again by the new layout
+}
+
+// SetClock set the logger clock, should call before logger real use
+func (l *Logger) SetClock(c Clock) {
+	l.clock = c
+}
+
+// SetLocation set the location of record time, like time.UTC
+func (l *Logger) SetLocation(loc *time.Location) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.location = loc
+	l.lastTime = 0 // format again in the new location
 }
 
 // SetLevel set the logger level
 func (l *Logger) SetLevel(lvl int) {
-	l.level = lvl
+	atomic.StoreInt32(&l.level, int32(lvl))
 }
 
 // WithFullPath set the logger with full path
@@ -212,12 +333,52 @@ func (l *Logger) Access(fmt string, args ...interface{}) {
 }
 
 func (l *Logger) deliverRecordToWriter(level int, f string, args ...interface{}) {
-	var msg string
-	var fi bytes.Buffer
+	l.deliverRecord(level, nil, f, args...)
+}
 
-	if level > l.level {
+func (l *Logger) deliverRecordWithFields(level int, fields []Field, f string, args ...interface{}) {
+	l.deliverRecord(level, fields, f, args...)
+}
+
+// deliverRecord must be called by a deliver func called by the log func, for the caller
+func (l *Logger) deliverRecord(level int, fields []Field, f string, args ...interface{}) {
+	if !l.enabled(level) {
+		l.metrics.countFiltered(level)
 		return
 	}
+	l.send(l.newRecord(4, level, fields, f, args...))
+}
+
+// send run hooks, then send the record to the logger goroutine.
+// Hooks run by the goroutine logs the record, not under any lock of the logger, so hooks can log
+func (l *Logger) send(r *Record) {
+	l.runHooks(r)
+	if l.isSynchronous() {
+		level := r.level
+		if !l.writeSynchronous(r) {
+			l.reject(r)
+			return
+		}
+		l.metrics.countRecord(level)
+		return
+	}
+	if !l.enqueue(r) {
+		l.reject(r)
+		return
+	}
+	l.metrics.countRecord(r.level)
+}
+
+// enabled report records of level are logged or not by the logger level
+func (l *Logger) enabled(level int) bool {
+	return int32(level) <= atomic.LoadInt32(&l.level)
+}
+
+// newRecord create record from the pool,
+// skip is the number of stack frames to the caller, as runtime.Caller
+func (l *Logger) newRecord(skip int, level int, fields []Field, f string, args ...interface{}) *Record {
+	var msg string
+	var fi bytes.Buffer
 
 	msg = f
 	sz := len(args)
@@ -230,7 +391,7 @@ func (l *Logger) deliverRecordToWriter(level int, f string, args ...interface{})
 	msg = fmt.Sprintf(msg, args...)
 
 	// source code, file and line num
-	pc, file, line, ok := runtime.Caller(2)
+	pc, file, line, ok := runtime.Caller(skip)
 	if ok {
 		fileName := path.Base(file)
 		if l.fullPath {
@@ -246,22 +407,86 @@ func (l *Logger) deliverRecordToWriter(level int, f string, args ...interface{})
 	}
 
 	// format time
-	now := time.Now()
-	l.lock.Lock() // avoid data race
-	if now.Unix() != l.lastTime {
-		l.lastTime = now.Unix()
-		l.lastTimeStr = now.Format(l.layout)
+	now := clockOrSystem(l.clock).Now()
+	l.lock.RLock()
+	if l.location != nil {
+		now = now.In(l.location)
 	}
-	lastTimeStr := l.lastTimeStr
-	l.lock.Unlock()
+	l.lock.RUnlock()
 
 	r := recordPool.Get().(*Record)
 	r.msg = msg
+	r.format = f
 	r.file = fi.String()
-	r.time = lastTimeStr
+	r.time = l.formatTime(now)
+	r.timestamp = now
 	r.level = level
+	r.fields = fields
+	return r
+}
+
+// writeRecord write record to all writers,
+// writers are locked so they are not replaced or closed while writing,
+// the record is written to the fallback writer if a writer accepts the record level is disabled or fails
+func (l *Logger) writeRecord(r *Record) {
+	p := l.errorPolicy()
+	l.writersLock.RLock()
+	defer l.writersLock.RUnlock()
+	fallback := false
+	for _, w := range l.writers {
+		if !levelsOf(w).Contains(r.level) {
+			continue
+		}
+		if !l.writerEnabled(w) {
+			fallback = true
+			continue
+		}
+		if err := writeWithRetry(w, r, p); err != nil {
+			l.writerError(w, WriterOpWrite, err)
+			fallback = true
+			continue
+		}
+		l.writerSucceeded(w)
+	}
+	if fallback {
+		l.writeFallback(p, r)
+	}
+}
 
-	l.records <- r
+// flushWriters flush all writers, disabled writers are flushed too for the records written before,
+// but their errors are not reported again
+func (l *Logger) flushWriters() {
+	start := time.Now()
+	l.writersLock.RLock()
+	defer l.writersLock.RUnlock()
+	for _, w := range l.writers {
+		if f, ok := w.(Flusher); ok {
+			if err := f.Flush(); err != nil && l.writerEnabled(w) {
+				l.writerError(w, WriterOpFlush, err)
+			}
+		}
+	}
+	flushFallback(l.errorPolicy())
+	l.metrics.observeFlush(time.Since(start))
+}
+
+// handleRecord write record to writers, or flush writers for the record sent by Sync,
+// hooks are run by the goroutine sends the record
+func (l *Logger) handleRecord(r *Record) {
+	if r.synced != nil {
+		l.flushWriters()
+		close(r.synced)
+		return
+	}
+	l.writeRecord(r)
+	recordPool.Put(r)
+}
+
+// handleRecordLocked handle record by the logger goroutine, not at the same time as synchronous writes
+func (l *Logger) handleRecordLocked(r *Record) {
+	l.synchronous.lock.Lock()
+	defer l.synchronous.lock.Unlock()
+	l.handleRecord(r)
 }
 
 func bootstrapLogWriter(logger *Logger) {
@@ -271,15 +496,11 @@ func bootstrapLogWriter(logger *Logger) {
 	)
 
 	if r, ok = <-logger.records; !ok {
-		logger.c <- true
+		close(logger.c)
 		return
 	}
 
-	for _, w := range logger.writers {
-		if err := w.Write(r); err != nil {
-			log.Printf("%v\n", err)
-		}
-	}
+	logger.handleRecordLocked(r)
 
 	flushTimer := time.NewTimer(logger.flushTimer)
 	rotateTimer := time.NewTimer(logger.rotateTimer)
@@ -288,36 +509,32 @@ func bootstrapLogWriter(logger *Logger) {
 		select {
 		case r, ok = <-logger.records:
 			if !ok {
-				logger.c <- true
+				close(logger.c)
 				return
 			}
 
-			for _, w := range logger.writers {
-				if err := w.Write(r); err != nil {
-					log.Printf("%v\n", err)
-				}
-			}
-
-			recordPool.Put(r)
+			logger.handleRecordLocked(r)
 
 		case <-flushTimer.C:
-			for _, w := range logger.writers {
-				if f, ok := w.(Flusher); ok {
-					if err := f.Flush(); err != nil {
-						log.Printf("%v\n", err)
-					}
-				}
-			}
+			logger.synchronous.lock.Lock()
+			logger.flushWriters()
+			logger.synchronous.lock.Unlock()
 			flushTimer.Reset(logger.flushTimer)
 
 		case <-rotateTimer.C:
+			logger.synchronous.lock.Lock()
+			logger.writersLock.RLock()
+			// disabled writers are rotated too, so they write files of the time when enabled again
 			for _, w := range logger.writers {
 				if r, ok := w.(Rotater); ok {
-					if err := r.Rotate(); err != nil {
-						log.Printf("%v\n", err)
+					if err := r.Rotate(); err != nil && logger.writerEnabled(w) {
+						logger.writerError(w, WriterOpRotate, err)
 					}
 				}
 			}
+			logger.writersLock.RUnlock()
+			rotateFallback(logger.errorPolicy())
+			logger.synchronous.lock.Unlock()
 			rotateTimer.Reset(logger.rotateTimer)
 		}
 	}
@@ -325,8 +542,8 @@ func bootstrapLogWriter(logger *Logger) {
 
 func init() {
 	loggerDefault = NewLogger()
-	loggerDefault.flushTimer = time.Millisecond * 500
-	loggerDefault.rotateTimer = time.Second * 10
+	loggerDefault.flushTimer = flushTimerDefault
+	loggerDefault.rotateTimer = rotateTimerDefault
 	recordPool = &sync.Pool{New: func() interface{} {
 		return &Record{}
 	}}
@@ -342,14 +559,24 @@ func Close() {
 	loggerDefault.Close()
 }
 
+// Sync block until the records logged before are written to writers, then flush writers
+func Sync() {
+	loggerDefault.Sync()
+}
+
 // SetLayout set the logger time layout, should call before logger real use
 func SetLayout(layout string) {
-	loggerDefault.layout = layout
+	loggerDefault.SetLayout(layout)
+}
+
+// SetLocation set the location of record time, like time.UTC
+func SetLocation(loc *time.Location) {
+	loggerDefault.SetLocation(loc)
 }
 
 // SetLevel set the logger level, should call before logger real use
 func SetLevel(lvl int) {
-	loggerDefault.level = lvl
+	loggerDefault.SetLevel(lvl)
 }
 
 // WithFullPath set the logger with full path, should call before logger real use
@@ -394,10 +621,8 @@ func Access(fmt string, args ...interface{}) {
 
 // The method is put here, so it's easy to test
 func getLevelDefault(flag string, defaultFlag int, writer string) int {
-	for i, f := range LevelFlags {
-		if strings.TrimSpace(strings.ToUpper(flag)) == f {
-			return i
-		}
+	if lvl, err := parseLevel(flag); err == nil {
+		return lvl
 	}
 	log.Printf("[golog] no matching level for writer(%v, flag:%v), use default level(%d, flag:%v)", writer, flag, defaultFlag, LevelFlags[defaultFlag])
 	return defaultFlag
@@ -0,0 +1,245 @@
+package golog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// flushDurationBuckets upper bounds in seconds of the flush duration histogram
+var flushDurationBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// WriterStats statistics of writer
+type WriterStats struct {
+	BytesWritten uint64 // bytes written to files
+	Rotations    uint64 // count of rotations
+}
+
+// StatsReporter writer reports statistics, like FileWriter
+type StatsReporter interface {
+	Stats() WriterStats
+}
+
+// writerErrorKey key of writer errors by writer name and operation
+type writerErrorKey struct {
+	writer string
+	op     string // write, flush or rotate
+}
+
+// loggerMetrics metrics of logger pipeline
+type loggerMetrics struct {
+	records  [DEBUG + 1]uint64 // records logged by level
+	filtered [DEBUG + 1]uint64 // records filtered by the logger level
+	dropped  [DEBUG + 1]uint64 // records dropped after shutdown by level
+
+	lock          sync.Mutex
+	writerErrors  map[writerErrorKey]uint64
+	flushBuckets  []uint64 // count of flushes by bucket, not cumulative
+	flushCount    uint64
+	flushDuration time.Duration
+}
+
+// countRecord count a record of level logged
+func (m *loggerMetrics) countRecord(level int) {
+	if level >= ACCESS && level <= DEBUG {
+		atomic.AddUint64(&m.records[level], 1)
+	}
+}
+
+// countFiltered count a record of level filtered by the logger level
+func (m *loggerMetrics) countFiltered(level int) {
+	if level >= ACCESS && level <= DEBUG {
+		atomic.AddUint64(&m.filtered[level], 1)
+	}
+}
+
+// countDropped count a record of level dropped
+func (m *loggerMetrics) countDropped(level int) {
+	if level >= ACCESS && level <= DEBUG {
+		atomic.AddUint64(&m.dropped[level], 1)
+	}
+}
+
+// countWriterError count an error of writer operation
+func (m *loggerMetrics) countWriterError(writer, op string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.writerErrors == nil {
+		m.writerErrors = make(map[writerErrorKey]uint64)
+	}
+	m.writerErrors[writerErrorKey{writer: writer, op: op}]++
+}
+
+// observeFlush observe duration of flushing writers
+func (m *loggerMetrics) observeFlush(d time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.flushBuckets == nil {
+		m.flushBuckets = make([]uint64, len(flushDurationBuckets))
+	}
+	for i, b := range flushDurationBuckets {
+		if d.Seconds() <= b {
+			m.flushBuckets[i]++
+			break
+		}
+	}
+	m.flushCount++
+	m.flushDuration += d
+}
+
+// writerName name of writer for metrics and errors, the config name or field,
+// or the type and the sequence of registering for writers registered by hand, like "*golog.FileWriter#1",
+// names don't change when config reload adds or removes writers. The caller holds writersLock
+func (l *Logger) writerName(w Writer) string {
+	for _, cw := range l.configured {
+		if cw.w == w {
+			return cw.name
+		}
+	}
+	if i, ok := l.registered[w]; ok {
+		return fmt.Sprintf("%T#%d", w, i)
+	}
+	return fmt.Sprintf("%T", w)
+}
+
+// metricsWriter write metrics in the Prometheus text exposition format
+type metricsWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (m *metricsWriter) header(name, typ, help string) {
+	m.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (m *metricsWriter) sample(name string, labels []string, value string) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(labels[i] + `="` + escapeLabel(labels[i+1]) + `"`)
+		}
+		b.WriteByte('}')
+	}
+	m.printf("%s %s\n", b.String(), value)
+}
+
+func (m *metricsWriter) printf(format string, args ...interface{}) {
+	if m.err == nil {
+		_, m.err = fmt.Fprintf(m.w, format, args...)
+	}
+}
+
+// escapeLabel escape label value of the text exposition format
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// WriteMetrics write metrics of logger in the Prometheus text exposition format
+func (l *Logger) WriteMetrics(out io.Writer) error {
+	m := &metricsWriter{w: bufio.NewWriter(out)}
+	metrics := l.metrics
+
+	m.header("golog_records_total", "counter", "Records logged by level.")
+	for level := ACCESS; level <= DEBUG; level++ {
+		m.sample("golog_records_total", []string{"level", strings.ToLower(LevelFlags[level])},
+			strconv.FormatUint(atomic.LoadUint64(&metrics.records[level]), 10))
+	}
+	m.header("golog_records_filtered_total", "counter", "Records filtered by the logger level.")
+	for level := ACCESS; level <= DEBUG; level++ {
+		m.sample("golog_records_filtered_total", []string{"level", strings.ToLower(LevelFlags[level])},
+			strconv.FormatUint(atomic.LoadUint64(&metrics.filtered[level]), 10))
+	}
+	m.header("golog_records_dropped_total", "counter", "Records dropped after shutdown by level.")
+	for level := ACCESS; level <= DEBUG; level++ {
+		m.sample("golog_records_dropped_total", []string{"level", strings.ToLower(LevelFlags[level])},
+			strconv.FormatUint(atomic.LoadUint64(&metrics.dropped[level]), 10))
+	}
+	m.header("golog_queue_length", "gauge", "Records queued to write.")
+	m.sample("golog_queue_length", nil, strconv.Itoa(len(l.records)))
+	m.header("golog_queue_capacity", "gauge", "Capacity of the records queue.")
+	m.sample("golog_queue_capacity", nil, strconv.Itoa(cap(l.records)))
+
+	metrics.lock.Lock()
+	keys := make([]writerErrorKey, 0, len(metrics.writerErrors))
+	for k := range metrics.writerErrors {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].writer != keys[j].writer {
+			return keys[i].writer < keys[j].writer
+		}
+		return keys[i].op < keys[j].op
+	})
+	m.header("golog_writer_errors_total", "counter", "Errors of writers by operation.")
+	for _, k := range keys {
+		m.sample("golog_writer_errors_total", []string{"writer", k.writer, "op", k.op},
+			strconv.FormatUint(metrics.writerErrors[k], 10))
+	}
+	m.header("golog_flush_duration_seconds", "histogram", "Duration of flushing writers.")
+	var cumulative uint64
+	for i, b := range flushDurationBuckets {
+		if metrics.flushBuckets != nil {
+			cumulative += metrics.flushBuckets[i]
+		}
+		m.sample("golog_flush_duration_seconds_bucket", []string{"le", formatFloat(b)}, strconv.FormatUint(cumulative, 10))
+	}
+	m.sample("golog_flush_duration_seconds_bucket", []string{"le", "+Inf"}, strconv.FormatUint(metrics.flushCount, 10))
+	m.sample("golog_flush_duration_seconds_sum", nil, formatFloat(metrics.flushDuration.Seconds()))
+	m.sample("golog_flush_duration_seconds_count", nil, strconv.FormatUint(metrics.flushCount, 10))
+	metrics.lock.Unlock()
+
+	l.writersLock.RLock()
+	type writerStats struct {
+		name  string
+		stats WriterStats
+	}
+	var stats []writerStats
+	for _, w := range l.writers {
+		if r, ok := w.(StatsReporter); ok {
+			stats = append(stats, writerStats{name: l.writerName(w), stats: r.Stats()})
+		}
+	}
+	l.writersLock.RUnlock()
+	m.header("golog_writer_bytes_total", "counter", "Bytes written by writers.")
+	for _, s := range stats {
+		m.sample("golog_writer_bytes_total", []string{"writer", s.name}, strconv.FormatUint(s.stats.BytesWritten, 10))
+	}
+	m.header("golog_writer_rotations_total", "counter", "Rotations of writers.")
+	for _, s := range stats {
+		m.sample("golog_writer_rotations_total", []string{"writer", s.name}, strconv.FormatUint(s.stats.Rotations, 10))
+	}
+
+	if m.err != nil {
+		return m.err
+	}
+	return m.w.Flush()
+}
+
+// MetricsHandler handler serves metrics of logger in the Prometheus text exposition format
+func (l *Logger) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = l.WriteMetrics(w)
+	})
+}
+
+// MetricsHandler handler serves metrics of the default logger
+func MetricsHandler() http.Handler {
+	return loggerDefault.MetricsHandler()
+}
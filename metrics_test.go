@@ -0,0 +1,99 @@
+package golog
+
+import (
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Init() error {
+	return nil
+}
+
+func (failingWriter) Write(*Record) error {
+	return errors.New("write failed")
+}
+
+func (failingWriter) Flush() error {
+	return errors.New("flush failed")
+}
+
+func Test_LoggerMetrics(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	l.SetLevel(COMMON)
+	l.Register(failingWriter{})
+	w := NewFileWriterWithOptions(FileWriterOptions{Filename: filepath.Join(t.TempDir(), "app.log")})
+	l.Register(w)
+	w.Rotate()
+
+	l.Error("e1")
+	l.Error("e2")
+	l.Common("c1")
+	l.Debug("dropped")
+	l.Sync()
+
+	rec := httptest.NewRecorder()
+	l.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type got %s", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE golog_records_total counter\n",
+		`golog_records_total{level="error"} 2` + "\n",
+		`golog_records_total{level="common"} 1` + "\n",
+		`golog_records_filtered_total{level="debug"} 1` + "\n",
+		`golog_records_dropped_total{level="debug"} 0` + "\n",
+		"golog_queue_capacity 16\n",
+		`golog_writer_errors_total{writer="golog.failingWriter#0",op="flush"} 1` + "\n",
+		`golog_writer_errors_total{writer="golog.failingWriter#0",op="write"} 3` + "\n",
+		`golog_flush_duration_seconds_bucket{le="+Inf"} 1` + "\n",
+		"golog_flush_duration_seconds_count 1\n",
+		`golog_writer_rotations_total{writer="*golog.FileWriter#1"} 0` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics should contain %q, got:\n%s", want, body)
+		}
+	}
+	if !strings.Contains(body, `golog_writer_bytes_total{writer="*golog.FileWriter#1"} `) ||
+		strings.Contains(body, `golog_writer_bytes_total{writer="*golog.FileWriter#1"} 0`) {
+		t.Errorf("bytes written should be counted, got:\n%s", body)
+	}
+}
+
+func Test_escapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escape got %s", got)
+	}
+}
+
+func Test_writerNameStable(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	configured := []configuredWriter{
+		{name: "app", key: "app", w: &memoryWriter{}},
+		{name: "file_writers[1]", key: "file_writers[1]", w: &memoryWriter{}},
+	}
+	if err := l.applyWriters(configured, false); err != nil {
+		t.Fatal(err)
+	}
+	w := &memoryWriter{}
+	l.Register(w)
+	if got := l.writerName(configured[1].w); got != "file_writers[1]" {
+		t.Errorf("configured writer name = %s", got)
+	}
+	if got := l.writerName(w); got != "*golog.memoryWriter#0" {
+		t.Errorf("registered writer name = %s", got)
+	}
+	if err := l.applyWriters(nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.writerName(w); got != "*golog.memoryWriter#0" {
+		t.Errorf("registered writer name after reload = %s", got)
+	}
+}
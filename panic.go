@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"runtime/debug"
+)
+
+// LogPanic log the recovered panic value at ERROR level with the stack trace and fields,
+// then sync the logger, so the record is written even if the program exits,
+// should be called by the deferred func which recovers the panic
+func (l *Logger) LogPanic(v interface{}, fields ...Field) {
+	l.deliverRecordWithFields(ERROR, fields, "panic: %v\n%s", v, debug.Stack())
+	l.Sync()
+}
+
+// Go run f in a new goroutine, a panic in f is recovered and logged at ERROR level
+func (l *Logger) Go(f func()) {
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				l.LogPanic(v)
+			}
+		}()
+		f()
+	}()
+}
+
+// LogPanic log the recovered panic value by the default logger, see Logger.LogPanic
+func LogPanic(v interface{}, fields ...Field) {
+	loggerDefault.deliverRecordWithFields(ERROR, fields, "panic: %v\n%s", v, debug.Stack())
+	loggerDefault.Sync()
+}
+
+// Go run f in a new goroutine, a panic in f is recovered and logged by the default logger
+func Go(f func()) {
+	loggerDefault.Go(f)
+}
@@ -0,0 +1,31 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_LoggerGo(t *testing.T) {
+	l := NewLoggerWithChanSize(8)
+	defer l.Close()
+	w := &memoryWriter{}
+	l.Register(w)
+
+	// the panic record is handled by the logger goroutine, records sent after it are handled after it
+	done := make(chan struct{})
+	l.AddHook(NewLevelSet(ERROR), func(*Record) { close(done) })
+	l.Go(func() {
+		panic("boom")
+	})
+	<-done
+	l.Sync()
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.lines) != 1 {
+		t.Fatalf("got %d records, want 1", len(w.lines))
+	}
+	if !strings.HasPrefix(w.lines[0], "ERROR panic: boom\n") || !strings.Contains(w.lines[0], "panic_test.go") {
+		t.Errorf("panic record got %q", w.lines[0])
+	}
+}
@@ -0,0 +1,205 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// time units of path variables, decide the rotation rule when a variable changes
+const (
+	unitNone = iota // not changed by time
+	unitSecond
+	unitMinute
+	unitHour
+	unitDay
+)
+
+// pathVariable variable of path pattern, like %Y
+type pathVariable struct {
+	unit    int  // time unit the variable changes by
+	byLevel bool // the variable changes by record level
+	format  func(now *time.Time, level int) string
+}
+
+// pathVariableTable path variables by the char after %
+var pathVariableTable map[byte]pathVariable
+
+// pathSegment literal text or variable of path pattern
+type pathSegment struct {
+	literal  string
+	variable *pathVariable
+}
+
+// pathPattern parsed path pattern, like "logs/%P-%L-%Y%M%D.log"
+type pathPattern []pathSegment
+
+// parsePathPattern parse path pattern, "%%" for a literal "%".
+// %W is the ISO week, so it goes with %G the ISO year, not %Y
+func parsePathPattern(pattern string) (pathPattern, error) {
+	p := make(pathPattern, 0, strings.Count(pattern, "%")*2+1)
+	var literal strings.Builder
+	used := make(map[byte]bool)
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c != '%' {
+			literal.WriteByte(c)
+			continue
+		}
+		if i+1 == len(pattern) {
+			return nil, errors.New("invalid rotate pattern % at the end of (" + pattern + ")")
+		}
+		i++
+		if pattern[i] == '%' {
+			literal.WriteByte('%')
+			continue
+		}
+		v, ok := pathVariableTable[pattern[i]]
+		if !ok {
+			return nil, fmt.Errorf("invalid rotate pattern %%%c in (%s)", pattern[i], pattern)
+		}
+		used[pattern[i]] = true
+		if literal.Len() > 0 {
+			p = append(p, pathSegment{literal: literal.String()})
+			literal.Reset()
+		}
+		p = append(p, pathSegment{variable: &v})
+	}
+	if literal.Len() > 0 {
+		p = append(p, pathSegment{literal: literal.String()})
+	}
+	if used['W'] && used['Y'] {
+		return nil, errors.New("invalid rotate pattern %W with %Y in (" + pattern + "), use %G the ISO year")
+	}
+	return p, nil
+}
+
+// format path of the time and level
+func (p pathPattern) format(now time.Time, level int) string {
+	var b strings.Builder
+	for _, s := range p {
+		if s.variable == nil {
+			b.WriteString(s.literal)
+			continue
+		}
+		b.WriteString(s.variable.format(&now, level))
+	}
+	return b.String()
+}
+
+// hasLevel report the pattern has level variable or not
+func (p pathPattern) hasLevel() bool {
+	for _, s := range p {
+		if s.variable != nil && s.variable.byLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// changedUnits units of the time variables changed from one time to another
+func (p pathPattern) changedUnits(from, to time.Time) map[int]bool {
+	units := make(map[int]bool)
+	for _, s := range p {
+		if s.variable == nil || s.variable.unit == unitNone {
+			continue
+		}
+		if s.variable.format(&from, anyLevel) != s.variable.format(&to, anyLevel) {
+			units[s.variable.unit] = true
+		}
+	}
+	return units
+}
+
+// timeVariable path variable of time, formatted as 0 padded number
+func timeVariable(unit int, width int, value func(now *time.Time) int) pathVariable {
+	return pathVariable{
+		unit: unit,
+		format: func(now *time.Time, _ int) string {
+			return fmt.Sprintf("%0*d", width, value(now))
+		},
+	}
+}
+
+// constVariable path variable never changes
+func constVariable(value string) pathVariable {
+	return pathVariable{
+		format: func(*time.Time, int) string {
+			return value
+		},
+	}
+}
+
+func getYear(now *time.Time) int {
+	return now.Year()
+}
+
+func getMonth(now *time.Time) int {
+	return int(now.Month())
+}
+
+func getDay(now *time.Time) int {
+	return now.Day()
+}
+
+func getHour(now *time.Time) int {
+	return now.Hour()
+}
+
+func getMin(now *time.Time) int {
+	return now.Minute()
+}
+
+func getSecond(now *time.Time) int {
+	return now.Second()
+}
+
+func getISOYear(now *time.Time) int {
+	year, _ := now.ISOWeek()
+	return year
+}
+
+func getWeek(now *time.Time) int {
+	_, week := now.ISOWeek()
+	return week
+}
+
+func getYearDay(now *time.Time) int {
+	return now.YearDay()
+}
+
+func getLevelName(_ *time.Time, level int) string {
+	if level < ACCESS || level > DEBUG {
+		return "all"
+	}
+	return strings.ToLower(LevelFlags[level])
+}
+
+func init() {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	program := filepath.Base(os.Args[0])
+	program = strings.TrimSuffix(program, filepath.Ext(program))
+
+	pathVariableTable = map[byte]pathVariable{
+		'Y': timeVariable(unitDay, 4, getYear),
+		'M': timeVariable(unitDay, 2, getMonth),
+		'D': timeVariable(unitDay, 2, getDay),
+		'G': timeVariable(unitDay, 4, getISOYear),
+		'W': timeVariable(unitDay, 2, getWeek),
+		'j': timeVariable(unitDay, 3, getYearDay),
+		'H': timeVariable(unitHour, 2, getHour),
+		'm': timeVariable(unitMinute, 2, getMin),
+		's': timeVariable(unitSecond, 2, getSecond),
+		'h': constVariable(hostname),
+		'p': constVariable(strconv.Itoa(os.Getpid())),
+		'P': constVariable(program),
+		'L': {byLevel: true, format: getLevelName},
+	}
+}
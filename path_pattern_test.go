@@ -0,0 +1,142 @@
+package golog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_PathPatternFormat(t *testing.T) {
+	hostname, _ := os.Hostname()
+	program := strings.TrimSuffix(filepath.Base(os.Args[0]), filepath.Ext(os.Args[0]))
+	now := time.Date(2026, 1, 4, 5, 6, 7, 0, time.UTC)
+
+	cases := []struct {
+		pattern string
+		level   int
+		want    string
+	}{
+		{"app.log", DEBUG, "app.log"},
+		{"app-%Y%M%D%H%m%s.log", DEBUG, "app-20260104050607.log"},
+		{"app-%G-W%W-%j.log", DEBUG, "app-2026-W01-004.log"},
+		{"%h/%P-%p.log", DEBUG, hostname + "/" + program + "-" + strconv.Itoa(os.Getpid()) + ".log"},
+		{"logs/%L-%Y%M%D.log", TRANSACTION, "logs/transaction-20260104.log"},
+		{"logs/%L.log", anyLevel, "logs/all.log"},
+		{"100%%-%D.log", DEBUG, "100%-04.log"},
+	}
+	for _, c := range cases {
+		p, err := parsePathPattern(c.pattern)
+		if err != nil {
+			t.Errorf("parse %q err %v", c.pattern, err)
+			continue
+		}
+		if got := p.format(now, c.level); got != c.want {
+			t.Errorf("format %q got %q, want %q", c.pattern, got, c.want)
+		}
+	}
+
+	// ISO year of the first days of the year may be the last year
+	p, _ := parsePathPattern("app-%G-W%W.log")
+	if got := p.format(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), DEBUG); got != "app-2026-W53.log" {
+		t.Errorf("ISO week got %q", got)
+	}
+
+	for _, pattern := range []string{"app-%Q.log", "app-%", "app-%Y-W%W.log"} {
+		if _, err := parsePathPattern(pattern); err == nil {
+			t.Errorf("parse %q should fail", pattern)
+		}
+	}
+}
+
+func Test_PathPatternChangedUnits(t *testing.T) {
+	p, err := parsePathPattern("app-%L-%Y%M%D%H.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 31, 23, 30, 0, 0, time.UTC)
+	units := p.changedUnits(from, from.Add(time.Minute*10))
+	if len(units) != 0 {
+		t.Errorf("changed units in the same hour %v", units)
+	}
+	units = p.changedUnits(from, from.Add(time.Hour))
+	if !units[unitDay] || !units[unitHour] || units[unitMinute] {
+		t.Errorf("changed units across month end %v", units)
+	}
+}
+
+func Test_FileWriterSplitByLevel(t *testing.T) {
+	dir := t.TempDir()
+	lg := newLoggerWithRecords(make(chan *Record, uint(16)))
+	w := NewFileWriterWithOptions(FileWriterOptions{Filename: filepath.Join(dir, "%P-%L-%Y%M%D.log")})
+	lg.Register(w)
+
+	lg.Error("split error")
+	lg.Access("split access")
+	lg.Error("split error again")
+	lg.Close()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	sort.Strings(matches)
+	if len(matches) != 2 {
+		t.Fatalf("got files %v, want access and error files", matches)
+	}
+	for _, file := range matches {
+		cnt, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Base(file)
+		switch {
+		case strings.Contains(name, "-access-"):
+			if strings.Count(string(cnt), "\n") != 1 || !strings.Contains(string(cnt), "split access") {
+				t.Errorf("%s got %q", name, cnt)
+			}
+		case strings.Contains(name, "-error-"):
+			if strings.Count(string(cnt), "\n") != 2 || strings.Contains(string(cnt), "split access") {
+				t.Errorf("%s got %q", name, cnt)
+			}
+		default:
+			t.Errorf("unexpected file %s", name)
+		}
+	}
+}
+
+func Test_FileWriterRotateSecondly(t *testing.T) {
+	dir := t.TempDir()
+	clock := &stepClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	w := NewFileWriterWithOptions(FileWriterOptions{
+		Filename:   filepath.Join(dir, "app-%H%m%s.log"),
+		Rotate:     true,
+		Secondly:   true,
+		MaxSeconds: 2,
+	})
+	w.SetClock(clock)
+	if err := w.Init(); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// rotated by writes, without the rotate timer
+	for i := 0; i < 5; i++ {
+		if err := w.Write(&Record{level: COMMON, time: "now", msg: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+		clock.now = clock.now.Add(time.Second)
+	}
+	w.Flush()
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.log"))
+	sort.Strings(matches)
+	var names []string
+	for _, m := range matches {
+		names = append(names, filepath.Base(m))
+	}
+	if want := []string{"app-120000.log", "app-120002.log", "app-120004.log"}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,130 @@
+package golog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// closeTimeoutDefault timeout of Close to drain records
+const closeTimeoutDefault = 10 * time.Second
+
+// loggerShutdown shutdown state of logger
+type loggerShutdown struct {
+	lock    sync.RWMutex  // held by senders, so records are not sent after the channel is closed
+	closed  bool          // records channel is closed
+	closing chan struct{} // closed when shutdown starts, unblocks the senders
+	stop    sync.Once
+	writers sync.Once // writers are flushed and closed once
+}
+
+func newLoggerShutdown() *loggerShutdown {
+	return &loggerShutdown{closing: make(chan struct{})}
+}
+
+// enqueue send the record to the logger goroutine, false if the logger is shut down
+func (l *Logger) enqueue(r *Record) bool {
+	s := l.shutdown
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.closed {
+		return false
+	}
+	select {
+	case l.records <- r:
+		return true
+	case <-s.closing:
+		return false
+	}
+}
+
+// reject count the record sent after shutdown as dropped, and write it to the fallback writer
+func (l *Logger) reject(r *Record) {
+	l.metrics.countDropped(r.level)
+	p := l.errorPolicy()
+	l.writeFallback(p, r)
+	flushFallback(p)
+	recordPool.Put(r)
+}
+
+// Shutdown stop accepting records, wait the queued records are written until ctx is done,
+// then flush and close writers. Records logged after shutdown are dropped,
+// or written to the fallback writer of the error policy.
+// The error of ctx is returned if records are not drained in time, writers are not closed then
+func (l *Logger) Shutdown(ctx context.Context) error {
+	s := l.shutdown
+	s.stop.Do(func() {
+		close(s.closing)
+		s.lock.Lock()
+		s.closed = true
+		close(l.records)
+		s.lock.Unlock()
+	})
+
+	select {
+	case <-l.c:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.writers.Do(l.closeWriters)
+	return nil
+}
+
+// closeWriters flush and close all writers, writers are removed from the logger.
+// The fallback writer is flushed but not closed, it receives the records logged after shutdown
+func (l *Logger) closeWriters() {
+	l.writersLock.Lock()
+	defer l.writersLock.Unlock()
+	for _, w := range l.writers {
+		l.closeWriter(w, l.writerName(w))
+		l.forgetWriter(w)
+	}
+	l.writers = nil
+	flushFallback(l.errorPolicy())
+}
+
+// Shutdown shut down the default logger
+func Shutdown(ctx context.Context) error {
+	return loggerDefault.Shutdown(ctx)
+}
+
+// ShutdownOnSignal shut down the logger within timeout on SIGINT or SIGTERM, or the signals given,
+// then the signal is raised again for the default behavior, like exiting.
+// Applications handle signals themselves should call Shutdown instead. Call stop to cancel it
+func (l *Logger) ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, signals...)
+	go func() {
+		select {
+		case sig := <-ch:
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			if err := l.Shutdown(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "[go-log] shutdown on signal %v err: %v\n", sig, err)
+			}
+			cancel()
+			signal.Stop(ch)
+			if p, err := os.FindProcess(os.Getpid()); err != nil || p.Signal(sig) != nil {
+				os.Exit(1)
+			}
+		case <-done:
+			signal.Stop(ch)
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// ShutdownOnSignal shut down the default logger on signals
+func ShutdownOnSignal(timeout time.Duration, signals ...os.Signal) (stop func()) {
+	return loggerDefault.ShutdownOnSignal(timeout, signals...)
+}
@@ -0,0 +1,103 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks writes until released, records flushes and closes
+type blockingWriter struct {
+	memoryWriter
+	release chan struct{}
+	calls   []string
+}
+
+func (w *blockingWriter) Write(r *Record) error {
+	if w.release != nil {
+		<-w.release
+	}
+	return w.memoryWriter.Write(r)
+}
+
+func (w *blockingWriter) Flush() error {
+	w.calls = append(w.calls, "flush")
+	return nil
+}
+
+func (w *blockingWriter) Close() error {
+	w.calls = append(w.calls, "close")
+	return nil
+}
+
+func Test_Shutdown(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	w := &blockingWriter{}
+	fallback := &memoryWriter{}
+	l.Register(w)
+	l.SetErrorPolicy(ErrorPolicy{Fallback: fallback})
+
+	l.Error("e1")
+	l.Common("c1")
+	if err := l.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("after")
+	l.Sync()
+	if err := l.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() again err = %v", err)
+	}
+	l.Close()
+
+	if want := []string{"ERROR e1", "COMMON c1"}; !reflect.DeepEqual(w.lines, want) {
+		t.Errorf("lines = %v, want %v", w.lines, want)
+	}
+	if want := []string{"flush", "close"}; !reflect.DeepEqual(w.calls, want) {
+		t.Errorf("calls = %v, want %v", w.calls, want)
+	}
+	if want := []string{"ERROR after"}; !reflect.DeepEqual(fallback.lines, want) {
+		t.Errorf("fallback = %v, want %v", fallback.lines, want)
+	}
+	if got := l.metrics.dropped[ERROR]; got != 1 {
+		t.Errorf("dropped = %d, want 1", got)
+	}
+}
+
+func Test_ShutdownTimeout(t *testing.T) {
+	l := NewLoggerWithChanSize(1)
+	w := &blockingWriter{release: make(chan struct{})}
+	l.Register(w)
+
+	l.Error("e1") // blocks the logger goroutine
+	l.Error("e2") // queued
+	var senders sync.WaitGroup
+	senders.Add(1)
+	go func() {
+		defer senders.Done()
+		l.Error("e3") // blocked by the full queue until shutdown
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() err = %v, want deadline exceeded", err)
+	}
+	senders.Wait()
+	if len(w.calls) != 0 {
+		t.Errorf("writer closed before drained: %v", w.calls)
+	}
+
+	close(w.release)
+	if err := l.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ERROR e1", "ERROR e2"}; !reflect.DeepEqual(w.lines, want) {
+		t.Errorf("lines = %v, want %v", w.lines, want)
+	}
+	if want := []string{"flush", "close"}; !reflect.DeepEqual(w.calls, want) {
+		t.Errorf("calls = %v, want %v", w.calls, want)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package golog
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_ShutdownOnSignal(t *testing.T) {
+	// keep the raised again signal from killing the test
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGUSR1)
+	defer signal.Stop(signals)
+
+	l := NewLoggerWithChanSize(16)
+	w := &blockingWriter{}
+	l.Register(w)
+	stop := l.ShutdownOnSignal(time.Second, syscall.SIGUSR1)
+	defer stop()
+
+	l.Error("e1")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-signals:
+		case <-time.After(2 * time.Second):
+			t.Fatal("signal not raised again")
+		}
+	}
+
+	if want := []string{"ERROR e1"}; !reflect.DeepEqual(w.lines, want) {
+		t.Errorf("lines = %v, want %v", w.lines, want)
+	}
+	if want := []string{"flush", "close"}; !reflect.DeepEqual(w.calls, want) {
+		t.Errorf("calls = %v, want %v", w.calls, want)
+	}
+}
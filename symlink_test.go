@@ -0,0 +1,67 @@
+package golog_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+func assertSymlink(t *testing.T, link, target string) {
+	t.Helper()
+	got, err := os.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != target {
+		t.Errorf("symlink %s points to %s, want %s", link, got, target)
+	}
+}
+
+func Test_FileWriterSymlink(t *testing.T) {
+	clock := gologtest.NewFakeClock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	dir := t.TempDir()
+	_, w, logDir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename:   "app-%Y%M%D%H%m.log",
+		Minutely:   true,
+		MaxMinutes: 1,
+		Symlink:    filepath.Join(dir, "app.current"),
+	})
+	link := filepath.Join(dir, "app.current")
+	target, err := filepath.Rel(dir, filepath.Join(logDir, "app-202610171200.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSymlink(t, link, target)
+
+	clock.Add(time.Minute)
+	if err = w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	target, _ = filepath.Rel(dir, filepath.Join(logDir, "app-202610171201.log"))
+	assertSymlink(t, link, target)
+	if _, err = os.Stat(link); err != nil {
+		t.Errorf("symlink target should exist: %v", err)
+	}
+}
+
+func Test_FileWriterSymlinkByLevel(t *testing.T) {
+	clock := gologtest.NewFakeClock(time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local))
+	dir := t.TempDir()
+	l, _, logDir := newRotateFileWriter(t, clock, golog.FileWriterOptions{
+		Filename: "app-%L-%Y%M%D.log",
+		Symlink:  filepath.Join(dir, "%L.current"),
+	})
+	l.Error("to error file")
+	l.Access("to access file")
+	l.Sync()
+
+	assertSymlink(t, filepath.Join(dir, "error.current"), filepath.Join("..", filepath.Base(logDir), "app-error-20261017.log"))
+	assertSymlink(t, filepath.Join(dir, "access.current"), filepath.Join("..", filepath.Base(logDir), "app-access-20261017.log"))
+	if _, err := os.Lstat(filepath.Join(dir, "debug.current")); !os.IsNotExist(err) {
+		t.Errorf("symlink of level without record should not exist, err %v", err)
+	}
+}
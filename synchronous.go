@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// synchronousMode records are written by the caller in synchronous mode
+type synchronousMode struct {
+	enabled     int32      // 1 in synchronous mode
+	flushLevels LevelSet   // writers are flushed after records of the levels
+	lock        sync.Mutex // held while writing, flushing and rotating writers in both modes
+}
+
+// SetSynchronous write records to writers by the caller under a lock instead of the logger goroutine,
+// writers are flushed after records of flushLevels, like LevelSetAll for CLIs.
+// Should call before logger real use
+func (l *Logger) SetSynchronous(enabled bool, flushLevels LevelSet) {
+	l.synchronous.lock.Lock()
+	defer l.synchronous.lock.Unlock()
+	l.synchronous.flushLevels = flushLevels
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&l.synchronous.enabled, v)
+}
+
+// SetSynchronous set synchronous mode of the default logger
+func SetSynchronous(enabled bool, flushLevels LevelSet) {
+	loggerDefault.SetSynchronous(enabled, flushLevels)
+}
+
+// isSynchronous report the logger is in synchronous mode or not
+func (l *Logger) isSynchronous() bool {
+	return atomic.LoadInt32(&l.synchronous.enabled) == 1
+}
+
+// writeSynchronous write the record by the caller, then flush writers by the flush levels,
+// false if the logger is shut down
+func (l *Logger) writeSynchronous(r *Record) bool {
+	s := l.shutdown
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.closed {
+		return false
+	}
+	l.synchronous.lock.Lock()
+	defer l.synchronous.lock.Unlock()
+	flush := l.synchronous.flushLevels.Contains(r.level)
+	l.handleRecord(r)
+	if flush {
+		l.flushWriters()
+	}
+	return true
+}
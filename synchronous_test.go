@@ -0,0 +1,75 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_Synchronous(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	w := &blockingWriter{}
+	l.Register(w)
+	l.SetSynchronous(true, NewLevelSet(ERROR))
+
+	l.Common("c1")
+	if want := []string{"COMMON c1"}; !reflect.DeepEqual(w.lines, want) {
+		t.Errorf("lines = %v, want %v", w.lines, want)
+	}
+	if len(w.calls) != 0 {
+		t.Errorf("calls = %v, want no flush", w.calls)
+	}
+	l.Error("e1")
+	if want := []string{"flush"}; !reflect.DeepEqual(w.calls, want) {
+		t.Errorf("calls = %v, want %v", w.calls, want)
+	}
+
+	l.Close()
+	l.Error("after")
+	if want := []string{"COMMON c1", "ERROR e1"}; !reflect.DeepEqual(w.lines, want) {
+		t.Errorf("lines = %v, want %v", w.lines, want)
+	}
+	if l.metrics.records[ERROR] != 1 || l.metrics.dropped[ERROR] != 1 {
+		t.Errorf("records = %d, dropped = %d", l.metrics.records[ERROR], l.metrics.dropped[ERROR])
+	}
+}
+
+func Test_SynchronousHookLogs(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	w := &memoryWriter{}
+	l.Register(w)
+	l.SetSynchronous(true, 0)
+	l.AddHook(NewLevelSet(ERROR), func(r *Record) { l.Common("hooked " + r.Msg()) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Error("e1")
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("hook logging deadlocked")
+	}
+	if want := []string{"COMMON hooked e1", "ERROR e1"}; !reflect.DeepEqual(w.lines, want) {
+		t.Errorf("lines = %v, want %v", w.lines, want)
+	}
+}
+
+func Test_SynchronousWithConfig(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	if err := setupLogger(l, LogConfig{Synchronous: true, FlushLevel: "error"}); err != nil {
+		t.Fatal(err)
+	}
+	if !l.isSynchronous() || l.synchronous.flushLevels != LevelsUpTo(ERROR) {
+		t.Errorf("synchronous = %v, flush levels = %v", l.isSynchronous(), l.synchronous.flushLevels)
+	}
+	if err := setupLogger(l, LogConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	if l.isSynchronous() {
+		t.Error("synchronous should be disabled")
+	}
+}
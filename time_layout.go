@@ -0,0 +1,72 @@
+package golog
+
+import (
+	"strconv"
+	"time"
+)
+
+// layouts of unix epoch time in numeric
+const (
+	LayoutUnix      = "unix"      // seconds
+	LayoutUnixMilli = "unixmilli" // milliseconds
+	LayoutUnixMicro = "unixmicro" // microseconds
+	LayoutUnixNano  = "unixnano"  // nanoseconds
+)
+
+// layouts with sub-second precision
+const (
+	LayoutMilli       = "2006/01/02 15:04:05.000"
+	LayoutMicro       = "2006/01/02 15:04:05.000000"
+	LayoutNano        = "2006/01/02 15:04:05.000000000"
+	LayoutRFC3339Nano = time.RFC3339Nano
+)
+
+// splitLayout split layout at the first fractional second, like ".000" or ",999",
+// the prefix only changes per second, so it can be cached
+func splitLayout(layout string) (prefix, suffix string) {
+	for i := 0; i+1 < len(layout); i++ {
+		if layout[i] != '.' && layout[i] != ',' {
+			continue
+		}
+		ch := layout[i+1]
+		if ch != '0' && ch != '9' {
+			continue
+		}
+		j := i + 1
+		for j < len(layout) && layout[j] == ch {
+			j++
+		}
+		// fractional second only if the digits end here
+		if j < len(layout) && layout[j] >= '0' && layout[j] <= '9' {
+			continue
+		}
+		return layout[:i], layout[i:]
+	}
+	return layout, ""
+}
+
+// formatTime format time by the logger layout, the part of layout before fractional second is cached per second
+func (l *Logger) formatTime(now time.Time) string {
+	l.lock.Lock() // avoid data race
+	defer l.lock.Unlock()
+
+	switch l.layout {
+	case LayoutUnix:
+		return strconv.FormatInt(now.Unix(), 10)
+	case LayoutUnixMilli:
+		return strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10)
+	case LayoutUnixMicro:
+		return strconv.FormatInt(now.UnixNano()/int64(time.Microsecond), 10)
+	case LayoutUnixNano:
+		return strconv.FormatInt(now.UnixNano(), 10)
+	}
+
+	if now.Unix() != l.lastTime {
+		l.lastTime = now.Unix()
+		l.lastTimeStr = now.Format(l.layoutPrefix)
+	}
+	if l.layoutSuffix == "" {
+		return l.lastTimeStr
+	}
+	return l.lastTimeStr + now.Format(l.layoutSuffix)
+}
@@ -0,0 +1,79 @@
+package golog
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_SplitLayout(t *testing.T) {
+	cases := []struct {
+		layout, prefix, suffix string
+	}{
+		{DefaultLayout, DefaultLayout, ""},
+		{TimestampLayout, "2006-01-02T15:04:05", ".000-0700"},
+		{LayoutRFC3339Nano, "2006-01-02T15:04:05", ".999999999Z07:00"},
+		{"15:04:05,000000", "15:04:05", ",000000"},
+		{"2006.01.02 15:04:05", "2006.01.02 15:04:05", ""},
+		{"2006.01.02 15:04:05.00", "2006.01.02 15:04:05", ".00"},
+	}
+	for _, c := range cases {
+		prefix, suffix := splitLayout(c.layout)
+		if prefix != c.prefix || suffix != c.suffix {
+			t.Errorf("split %q got (%q, %q), want (%q, %q)", c.layout, prefix, suffix, c.prefix, c.suffix)
+		}
+	}
+}
+
+func Test_LoggerFormatTime(t *testing.T) {
+	lg := newLoggerWithRecords(make(chan *Record, uint(1)))
+	defer lg.Close()
+	base := time.Date(2026, 10, 18, 12, 0, 1, 0, time.UTC)
+	times := []time.Time{
+		base.Add(time.Millisecond * 5),
+		base.Add(time.Millisecond*123 + time.Microsecond*456 + 789),
+		base.Add(time.Second + time.Millisecond*7),
+	}
+
+	cases := []struct {
+		layout string
+		want   []string
+	}{
+		{LayoutMilli, []string{"2026/10/18 12:00:01.005", "2026/10/18 12:00:01.123", "2026/10/18 12:00:02.007"}},
+		{LayoutMicro, []string{"2026/10/18 12:00:01.005000", "2026/10/18 12:00:01.123456", "2026/10/18 12:00:02.007000"}},
+		{LayoutNano, []string{"2026/10/18 12:00:01.005000000", "2026/10/18 12:00:01.123456789", "2026/10/18 12:00:02.007000000"}},
+		{LayoutRFC3339Nano, []string{"2026-10-18T12:00:01.005Z", "2026-10-18T12:00:01.123456789Z", "2026-10-18T12:00:02.007Z"}},
+		{DefaultLayout, []string{"2026/10/18 12:00:01", "2026/10/18 12:00:01", "2026/10/18 12:00:02"}},
+		{LayoutUnix, []string{"1792324801", "1792324801", "1792324802"}},
+		{LayoutUnixMilli, []string{"1792324801005", "1792324801123", "1792324802007"}},
+		{LayoutUnixMicro, []string{"1792324801005000", "1792324801123456", "1792324802007000"}},
+		{LayoutUnixNano, []string{"1792324801005000000", "1792324801123456789", "1792324802007000000"}},
+	}
+	for _, c := range cases {
+		lg.SetLayout(c.layout)
+		for i, now := range times {
+			if got := lg.formatTime(now); got != c.want[i] {
+				t.Errorf("layout %q time %d got %q, want %q", c.layout, i, got, c.want[i])
+			}
+		}
+	}
+}
+
+func Test_RecordTimestamp(t *testing.T) {
+	lg := NewLoggerWithChanSize(1)
+	defer lg.Close()
+	lg.SetLayout(LayoutMilli)
+	var timestamp time.Time
+	var formatted string
+	lg.AddHook(LevelSetAll, func(r *Record) {
+		timestamp, formatted = r.Timestamp(), r.Time()
+	})
+
+	before := time.Now()
+	lg.Common("timestamp")
+	if timestamp.Before(before) || timestamp.After(time.Now()) {
+		t.Errorf("record timestamp %v not the time logged", timestamp)
+	}
+	if formatted != timestamp.Format(LayoutMilli) {
+		t.Errorf("record time %s, want %s", formatted, timestamp.Format(LayoutMilli))
+	}
+}
@@ -0,0 +1,181 @@
+package golog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// outcomes of transaction
+const (
+	TxnCommit   = "commit"
+	TxnRollback = "rollback"
+)
+
+// txnDebugLimitDefault max DEBUG records buffered by a transaction
+const txnDebugLimitDefault = 1000
+
+// Txn transaction scoped logger, every record is stamped by the transaction id,
+// DEBUG records are buffered and written only if the transaction is rolled back.
+// Buffered records are written on rollback even if the logger level is above DEBUG, on purpose,
+// levels of writers still apply
+type Txn struct {
+	logger *Logger
+	id     string
+	name   string
+	start  time.Time
+
+	lock         sync.Mutex
+	debug        []*Record // DEBUG records buffered, the last debugLimit ones
+	debugLimit   int
+	debugDropped int // oldest DEBUG records dropped over debugLimit
+	ended        bool
+}
+
+// BeginTxn begin a transaction, log the begin record with fields at TRANSACTION level
+func (l *Logger) BeginTxn(name string, fields ...Field) *Txn {
+	return l.beginTxn(name, fields)
+}
+
+// BeginTxn begin a transaction by the default logger
+func BeginTxn(name string, fields ...Field) *Txn {
+	return loggerDefault.beginTxn(name, fields)
+}
+
+func (l *Logger) beginTxn(name string, fields []Field) *Txn {
+	t := &Txn{logger: l, id: newTxnID(), name: name, start: clockOrSystem(l.clock).Now(), debugLimit: txnDebugLimitDefault}
+	t.send(4, TRANSACTION, append([]Field{{Key: "txn", Value: name}}, fields...), "begin %s", name)
+	return t
+}
+
+// newTxnID random transaction id
+func newTxnID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format("150405.000")))
+	}
+	return hex.EncodeToString(b)
+}
+
+// ID transaction id
+func (t *Txn) ID() string {
+	return t.id
+}
+
+// SetDebugLimit set the max DEBUG records buffered, the oldest are dropped over it,
+// 1000 by default, reset to the default if n <= 0
+func (t *Txn) SetDebugLimit(n int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if n <= 0 {
+		n = txnDebugLimitDefault
+	}
+	t.debugLimit = n
+	t.dropDebug()
+}
+
+// Debug level, buffered until the transaction ends, the oldest buffered record is dropped over the debug limit
+func (t *Txn) Debug(fmt string, args ...interface{}) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.ended {
+		t.send(3, DEBUG, nil, fmt, args...)
+		return
+	}
+	t.debug = append(t.debug, t.logger.newRecord(2, DEBUG, t.fields(nil), fmt, args...))
+	t.dropDebug()
+}
+
+// dropDebug drop the oldest buffered records over the debug limit
+func (t *Txn) dropDebug() {
+	for len(t.debug) > t.debugLimit {
+		recordPool.Put(t.debug[0])
+		t.debug[0] = nil
+		t.debug = t.debug[1:]
+		t.debugDropped++
+	}
+}
+
+// Common level
+func (t *Txn) Common(fmt string, args ...interface{}) {
+	t.send(3, COMMON, nil, fmt, args...)
+}
+
+// Abnormal level
+func (t *Txn) Abnormal(fmt string, args ...interface{}) {
+	t.send(3, ABNORMAL, nil, fmt, args...)
+}
+
+// Transaction level
+func (t *Txn) Transaction(fmt string, args ...interface{}) {
+	t.send(3, TRANSACTION, nil, fmt, args...)
+}
+
+// Error level
+func (t *Txn) Error(fmt string, args ...interface{}) {
+	t.send(3, ERROR, nil, fmt, args...)
+}
+
+// Access level
+func (t *Txn) Access(fmt string, args ...interface{}) {
+	t.send(3, ACCESS, nil, fmt, args...)
+}
+
+// Commit end the transaction, log the commit record with duration, buffered DEBUG records are dropped
+func (t *Txn) Commit(fields ...Field) {
+	t.end(TxnCommit, nil, fields)
+}
+
+// Rollback end the transaction, write the buffered DEBUG records regardless of the logger level,
+// then log the rollback record with duration, err and debug_dropped if buffered records are dropped
+func (t *Txn) Rollback(err error, fields ...Field) {
+	t.end(TxnRollback, err, fields)
+}
+
+// end end the transaction once, called by Commit or Rollback
+func (t *Txn) end(outcome string, err error, fields []Field) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.ended {
+		return
+	}
+	t.ended = true
+	if outcome == TxnRollback {
+		for _, r := range t.debug {
+			t.logger.send(r)
+		}
+	} else {
+		for _, r := range t.debug {
+			recordPool.Put(r)
+		}
+	}
+	t.debug = nil
+
+	ended := []Field{
+		{Key: "txn", Value: t.name},
+		{Key: "outcome", Value: outcome},
+		{Key: "duration", Value: clockOrSystem(t.logger.clock).Now().Sub(t.start)},
+	}
+	if err != nil {
+		ended = append(ended, Field{Key: "error", Value: err.Error()})
+	}
+	if outcome == TxnRollback && t.debugDropped > 0 {
+		ended = append(ended, Field{Key: "debug_dropped", Value: t.debugDropped})
+	}
+	t.send(4, TRANSACTION, append(ended, fields...), "%s %s", outcome, t.name)
+}
+
+// fields of record, the transaction id first
+func (t *Txn) fields(fields []Field) []Field {
+	return append([]Field{{Key: "txn_id", Value: t.id}}, fields...)
+}
+
+// send log the record by the logger level, skip is the stack frames from newRecord to the caller
+func (t *Txn) send(skip int, level int, fields []Field, fmt string, args ...interface{}) {
+	if !t.logger.enabled(level) {
+		t.logger.metrics.countFiltered(level)
+		return
+	}
+	t.logger.send(t.logger.newRecord(skip, level, t.fields(fields), fmt, args...))
+}
@@ -0,0 +1,86 @@
+package golog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	golog "github.com/legofun/go-log"
+	"github.com/legofun/go-log/gologtest"
+)
+
+func Test_TxnCommit(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	clock := gologtest.NewFakeClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	l.SetClock(clock)
+
+	txn := l.BeginTxn("transfer", golog.Field{Key: "amount", Value: 100})
+	txn.Debug("debug dropped on commit")
+	txn.Common("checked")
+	txn.Access("accessed")
+	clock.Add(30 * time.Millisecond)
+	txn.Commit()
+	txn.Rollback(errors.New("after commit"))
+
+	records := o.Records()
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4", len(records))
+	}
+	for _, r := range records {
+		gologtest.AssertField(t, r, "txn_id", txn.ID())
+		gologtest.AssertCaller(t, r, "txn_test.go")
+	}
+	gologtest.AssertLevel(t, records[0], golog.TRANSACTION)
+	gologtest.AssertMessage(t, records[0], "begin transfer")
+	gologtest.AssertField(t, records[0], "amount", 100)
+	gologtest.AssertMessage(t, records[1], "checked")
+	gologtest.AssertLevel(t, records[2], golog.ACCESS)
+	gologtest.AssertMessage(t, records[2], "accessed")
+	gologtest.AssertMessage(t, records[3], "commit transfer")
+	gologtest.AssertField(t, records[3], "outcome", golog.TxnCommit)
+	gologtest.AssertField(t, records[3], "duration", 30*time.Millisecond)
+}
+
+func Test_TxnRollback(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+	l.SetLevel(golog.COMMON)
+
+	txn := l.BeginTxn("transfer")
+	txn.Debug("step %d", 1)
+	txn.Debug("step %d", 2)
+	txn.Rollback(errors.New("insufficient funds"))
+
+	records := o.Records()
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4", len(records))
+	}
+	gologtest.AssertMessage(t, records[1], "step 1")
+	gologtest.AssertLevel(t, records[1], golog.DEBUG)
+	gologtest.AssertCaller(t, records[1], "txn_test.go")
+	gologtest.AssertMessage(t, records[2], "step 2")
+	gologtest.AssertMessage(t, records[3], "rollback transfer")
+	gologtest.AssertField(t, records[3], "outcome", golog.TxnRollback)
+	gologtest.AssertField(t, records[3], "error", "insufficient funds")
+	if records[1].Timestamp().After(records[3].Timestamp()) {
+		t.Error("buffered record should keep its time")
+	}
+}
+
+func Test_TxnDebugLimit(t *testing.T) {
+	l, o := gologtest.NewLogger(t)
+
+	txn := l.BeginTxn("transfer")
+	txn.SetDebugLimit(2)
+	txn.Debug("step %d", 1)
+	txn.Debug("step %d", 2)
+	txn.Debug("step %d", 3)
+	txn.Rollback(errors.New("timeout"))
+
+	records := o.Records()
+	if len(records) != 4 {
+		t.Fatalf("got %d records, want 4", len(records))
+	}
+	gologtest.AssertMessage(t, records[1], "step 2")
+	gologtest.AssertMessage(t, records[2], "step 3")
+	gologtest.AssertField(t, records[3], "debug_dropped", 1)
+}
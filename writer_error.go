@@ -0,0 +1,216 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// writer operations of WriterError
+const (
+	WriterOpWrite  = "write"
+	WriterOpFlush  = "flush"
+	WriterOpRotate = "rotate"
+	WriterOpClose  = "close"
+)
+
+// writerReenableDefault default time to re-enable a disabled writer
+const writerReenableDefault = 30 * time.Second
+
+// WriterError error of writer operation
+type WriterError struct {
+	Writer   string // name of the writer, the config name or the type
+	Op       string // WriterOpWrite, WriterOpFlush, WriterOpRotate or WriterOpClose
+	Err      error
+	Disabled bool // the writer is disabled by the error
+}
+
+func (e *WriterError) Error() string {
+	s := fmt.Sprintf("[go-log] writer %s %s err: %v", e.Writer, e.Op, e.Err)
+	if e.Disabled {
+		s += ", disabled"
+	}
+	return s
+}
+
+func (e *WriterError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorHandler handler of writer errors, called by the logger goroutine, should return fast
+type ErrorHandler func(err *WriterError)
+
+// stderrErrorHandler default error handler, print errors to stderr,
+// not by the log package which may be discarded
+func stderrErrorHandler(err *WriterError) {
+	fmt.Fprintln(os.Stderr, err.Error())
+}
+
+// ErrorPolicy policy of writer errors
+type ErrorPolicy struct {
+	// Retries times to retry a failed write, the logger goroutine is blocked while retrying
+	Retries int
+	// RetryBackoff backoff before the first retry, doubled every retry
+	RetryBackoff time.Duration
+	// Transient report the error should be retried, all errors are retried if nil
+	Transient func(err error) bool
+	// DisableAfter disable the writer after consecutive failures, never disabled if 0
+	DisableAfter int
+	// ReenableAfter try the disabled writer again after it, 30s by default
+	ReenableAfter time.Duration
+	// Fallback writer receives records failed or skipped by disabled writers, like a console writer,
+	// flushed and rotated with the writers, and flushed but not closed on shutdown
+	Fallback Writer
+}
+
+// writerState failures of writer
+type writerState struct {
+	failures      int
+	disabledUntil time.Time // disabled before it if not zero
+}
+
+// writerErrors error handling state of logger
+type writerErrors struct {
+	lock    sync.Mutex
+	handler ErrorHandler
+	policy  ErrorPolicy
+	states  map[Writer]*writerState
+}
+
+// SetErrorHandler set the handler of writer errors, errors are printed to stderr by default
+func (l *Logger) SetErrorHandler(h ErrorHandler) {
+	l.writerErrors.lock.Lock()
+	defer l.writerErrors.lock.Unlock()
+	l.writerErrors.handler = h
+}
+
+// SetErrorPolicy set the policy of writer errors, the fallback writer should be inited
+func (l *Logger) SetErrorPolicy(p ErrorPolicy) {
+	l.writerErrors.lock.Lock()
+	defer l.writerErrors.lock.Unlock()
+	l.writerErrors.policy = p
+}
+
+// SetErrorHandler set the handler of writer errors of the default logger
+func SetErrorHandler(h ErrorHandler) {
+	loggerDefault.SetErrorHandler(h)
+}
+
+// SetErrorPolicy set the policy of writer errors of the default logger
+func SetErrorPolicy(p ErrorPolicy) {
+	loggerDefault.SetErrorPolicy(p)
+}
+
+// errorPolicy the current policy
+func (l *Logger) errorPolicy() ErrorPolicy {
+	l.writerErrors.lock.Lock()
+	defer l.writerErrors.lock.Unlock()
+	return l.writerErrors.policy
+}
+
+// writerEnabled report the writer is enabled or not, a disabled writer is enabled again after ReenableAfter
+func (l *Logger) writerEnabled(w Writer) bool {
+	l.writerErrors.lock.Lock()
+	defer l.writerErrors.lock.Unlock()
+	s, ok := l.writerErrors.states[w]
+	if !ok || s.disabledUntil.IsZero() {
+		return true
+	}
+	return !clockOrSystem(l.clock).Now().Before(s.disabledUntil)
+}
+
+// writerSucceeded reset failures of the writer
+func (l *Logger) writerSucceeded(w Writer) {
+	l.writerErrors.lock.Lock()
+	defer l.writerErrors.lock.Unlock()
+	delete(l.writerErrors.states, w)
+}
+
+// forgetWriter drop the state of the writer removed from the logger
+func (l *Logger) forgetWriter(w Writer) {
+	l.writerErrors.lock.Lock()
+	defer l.writerErrors.lock.Unlock()
+	delete(l.writerErrors.states, w)
+}
+
+// writerError handle the error of writer operation, disable the writer by the policy,
+// the caller holds writersLock
+func (l *Logger) writerError(w Writer, op string, err error) {
+	l.namedWriterError(w, l.writerName(w), op, err)
+}
+
+// namedWriterError handle the error of writer operation with the writer name
+func (l *Logger) namedWriterError(w Writer, name string, op string, err error) {
+	l.metrics.countWriterError(name, op)
+
+	l.writerErrors.lock.Lock()
+	if l.writerErrors.states == nil {
+		l.writerErrors.states = make(map[Writer]*writerState)
+	}
+	s, ok := l.writerErrors.states[w]
+	if !ok {
+		s = &writerState{}
+		l.writerErrors.states[w] = s
+	}
+	s.failures++
+	p := l.writerErrors.policy
+	disabled := p.DisableAfter > 0 && s.failures >= p.DisableAfter
+	if disabled {
+		reenable := p.ReenableAfter
+		if reenable <= 0 {
+			reenable = writerReenableDefault
+		}
+		s.disabledUntil = clockOrSystem(l.clock).Now().Add(reenable)
+	}
+	handler := l.writerErrors.handler
+	l.writerErrors.lock.Unlock()
+
+	if handler == nil {
+		handler = stderrErrorHandler
+	}
+	handler(&WriterError{Writer: name, Op: op, Err: err, Disabled: disabled})
+}
+
+// writeWithRetry write the record, retry transient errors by the policy
+func writeWithRetry(w Writer, r *Record, p ErrorPolicy) error {
+	err := w.Write(r)
+	backoff := p.RetryBackoff
+	for i := 0; err != nil && i < p.Retries; i++ {
+		if p.Transient != nil && !p.Transient(err) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+		err = w.Write(r)
+	}
+	return err
+}
+
+// flushFallback flush the fallback writer
+func flushFallback(p ErrorPolicy) {
+	if f, ok := p.Fallback.(Flusher); ok {
+		if err := f.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "[go-log] fallback writer flush err: %v\n", err)
+		}
+	}
+}
+
+// rotateFallback rotate the fallback writer
+func rotateFallback(p ErrorPolicy) {
+	if r, ok := p.Fallback.(Rotater); ok {
+		if err := r.Rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "[go-log] fallback writer rotate err: %v\n", err)
+		}
+	}
+}
+
+// writeFallback write the record failed or skipped by a writer to the fallback writer
+func (l *Logger) writeFallback(p ErrorPolicy, r *Record) {
+	if p.Fallback == nil {
+		return
+	}
+	if err := p.Fallback.Write(r); err != nil {
+		fmt.Fprintf(os.Stderr, "[go-log] fallback writer err: %v\n", err)
+	}
+}
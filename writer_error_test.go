@@ -0,0 +1,227 @@
+package golog
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+// flakyWriter fails the first failures writes
+type flakyWriter struct {
+	lock     sync.Mutex
+	failures int
+	writes   int
+	lines    []string
+}
+
+func (w *flakyWriter) Init() error {
+	return nil
+}
+
+func (w *flakyWriter) Write(r *Record) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.writes++
+	if w.writes <= w.failures {
+		return errFlaky
+	}
+	w.lines = append(w.lines, r.Msg())
+	return nil
+}
+
+func (w *flakyWriter) Lines() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]string(nil), w.lines...)
+}
+
+func Test_WriterErrorRetry(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	w := &flakyWriter{failures: 2}
+	l.Register(w)
+	var errs []*WriterError
+	l.SetErrorHandler(func(err *WriterError) { errs = append(errs, err) })
+	l.SetErrorPolicy(ErrorPolicy{Retries: 2, RetryBackoff: time.Millisecond})
+
+	l.Error("retried")
+	l.Sync()
+
+	if lines := w.Lines(); !reflect.DeepEqual(lines, []string{"retried"}) {
+		t.Errorf("lines = %v", lines)
+	}
+	if len(errs) != 0 {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func Test_WriterErrorNotTransient(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	w := &flakyWriter{failures: 1}
+	l.Register(w)
+	var errs []*WriterError
+	l.SetErrorHandler(func(err *WriterError) { errs = append(errs, err) })
+	l.SetErrorPolicy(ErrorPolicy{
+		Retries:   2,
+		Transient: func(err error) bool { return !errors.Is(err, errFlaky) },
+	})
+
+	l.Error("lost")
+	l.Error("written")
+	l.Sync()
+
+	if lines := w.Lines(); !reflect.DeepEqual(lines, []string{"written"}) {
+		t.Errorf("lines = %v", lines)
+	}
+	if len(errs) != 1 || errs[0].Op != WriterOpWrite || !errors.Is(errs[0], errFlaky) || errs[0].Disabled {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func Test_WriterErrorDisableAndFallback(t *testing.T) {
+	clock := &stepClock{now: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	l.SetClock(clock)
+	w := &flakyWriter{failures: 3}
+	fallback := &memoryWriter{}
+	l.Register(w)
+	var errs []*WriterError
+	l.SetErrorHandler(func(err *WriterError) { errs = append(errs, err) })
+	l.SetErrorPolicy(ErrorPolicy{DisableAfter: 2, ReenableAfter: time.Minute, Fallback: fallback})
+
+	l.Error("e1")
+	l.Error("e2")
+	l.Error("e3") // skipped by the disabled writer
+	l.Sync()
+	if len(errs) != 2 || errs[0].Disabled || !errs[1].Disabled {
+		t.Fatalf("errors = %v", errs)
+	}
+	if w.writes != 2 {
+		t.Errorf("writes = %d, want 2", w.writes)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	l.Error("e4") // fails again, disabled at once
+	l.Error("e5")
+	l.Sync()
+	if len(errs) != 3 || !errs[2].Disabled {
+		t.Fatalf("errors = %v", errs)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	l.Error("e6")
+	l.Error("e7")
+	l.Sync()
+
+	if lines := w.Lines(); !reflect.DeepEqual(lines, []string{"e6", "e7"}) {
+		t.Errorf("lines = %v", lines)
+	}
+	want := []string{"ERROR e1", "ERROR e2", "ERROR e3", "ERROR e4", "ERROR e5"}
+	fallback.lock.Lock()
+	defer fallback.lock.Unlock()
+	if !reflect.DeepEqual(fallback.lines, want) {
+		t.Errorf("fallback = %v, want %v", fallback.lines, want)
+	}
+}
+
+func Test_WriterErrorDisabledLevels(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	access := &flakyWriter{failures: 1}
+	all := &memoryWriter{}
+	fallback := &memoryWriter{}
+	l.Register(LevelFilter(access, NewLevelSet(ACCESS)))
+	l.Register(all)
+	l.SetErrorHandler(func(*WriterError) {})
+	l.SetErrorPolicy(ErrorPolicy{DisableAfter: 1, ReenableAfter: time.Hour, Fallback: fallback})
+
+	l.Access("a1") // fails, the access writer is disabled
+	l.Debug("d1")
+	l.Common("c1")
+	l.Access("a2")
+	l.Sync()
+
+	if want := []string{"ACCESS a1", "ACCESS a2"}; !reflect.DeepEqual(fallback.lines, want) {
+		t.Errorf("fallback = %v, want %v", fallback.lines, want)
+	}
+	if want := []string{"ACCESS a1", "DEBUG d1", "COMMON c1", "ACCESS a2"}; !reflect.DeepEqual(all.lines, want) {
+		t.Errorf("lines = %v, want %v", all.lines, want)
+	}
+}
+
+func Test_WriterErrorForgetRemoved(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	configured := &flakyWriter{failures: 1}
+	registered := &flakyWriter{failures: 1}
+	l.SetErrorHandler(func(*WriterError) {})
+	l.Register(registered)
+	if err := l.applyWriters([]configuredWriter{{name: "app", key: "app", w: configured}}, false); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("failed")
+	l.Sync()
+	if len(l.writerErrors.states) != 2 {
+		t.Fatalf("states = %v", l.writerErrors.states)
+	}
+
+	if err := l.applyWriters(nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := l.writerErrors.states[configured]; ok || len(l.writerErrors.states) != 1 {
+		t.Errorf("state of removed writer kept: %v", l.writerErrors.states)
+	}
+	l.Close()
+	if len(l.writerErrors.states) != 0 {
+		t.Errorf("states after close = %v", l.writerErrors.states)
+	}
+}
+
+func Test_WriterErrorCloseRemoved(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	defer l.Close()
+	var errs []*WriterError
+	l.SetErrorHandler(func(err *WriterError) { errs = append(errs, err) })
+	if err := l.applyWriters([]configuredWriter{{name: "app", key: "app", w: failingWriter{}}}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.applyWriters(nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Writer != "app" || errs[0].Op != WriterOpFlush {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func Test_WriterErrorFallbackFlushed(t *testing.T) {
+	l := NewLoggerWithChanSize(16)
+	fallback := &blockingWriter{}
+	l.SetErrorPolicy(ErrorPolicy{Fallback: fallback})
+	l.Sync()
+	if len(fallback.calls) == 0 {
+		t.Error("fallback not flushed with writers")
+	}
+	l.Close()
+	l.Error("after")
+	for _, call := range fallback.calls {
+		if call != "flush" {
+			t.Errorf("fallback calls = %v, want flushes only", fallback.calls)
+			break
+		}
+	}
+	if n := len(fallback.calls); n < 3 || !reflect.DeepEqual(fallback.lines, []string{"ERROR after"}) {
+		t.Errorf("calls = %v, lines = %v", fallback.calls, fallback.lines)
+	}
+}
+
+func Test_WriterErrorString(t *testing.T) {
+	err := &WriterError{Writer: "app", Op: WriterOpFlush, Err: errFlaky, Disabled: true}
+	if got, want := err.Error(), "[go-log] writer app flush err: flaky, disabled"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// writer types of the builtin writer factories
+const (
+	WriterTypeConsole = "console"
+	WriterTypeFile    = "file"
+)
+
+// WriterFactory create writer with the type-specific options
+type WriterFactory func(options json.RawMessage) (Writer, error)
+
+// WriterConfig config of a writer in LogConfig.Writers
+type WriterConfig struct {
+	// Type of the writer, used to find the writer factory
+	Type string `json:"type" mapstructure:"type"`
+	// Name of the writer, for logs and config errors
+	Name string `json:"name" mapstructure:"name"`
+	// Options type-specific options, passed to the writer factory
+	Options json.RawMessage `json:"options" mapstructure:"options"`
+}
+
+// WriterValidator validate the type-specific options without creating the writer
+type WriterValidator func(options json.RawMessage) error
+
+var (
+	writerFactories     = make(map[string]WriterFactory)
+	writerValidators    = make(map[string]WriterValidator)
+	writerFactoriesLock sync.RWMutex
+)
+
+// RegisterWriterFactory register writer factory for the writer type,
+// the factory registered later replaces the former one of the same type
+func RegisterWriterFactory(typ string, factory func(json.RawMessage) (Writer, error)) {
+	writerFactoriesLock.Lock()
+	defer writerFactoriesLock.Unlock()
+	writerFactories[typ] = factory
+}
+
+// RegisterWriterValidator register options validator for the writer type, used by LogConfig.Validate,
+// options of types without validator are only checked to be valid JSON
+func RegisterWriterValidator(typ string, validator func(json.RawMessage) error) {
+	writerFactoriesLock.Lock()
+	defer writerFactoriesLock.Unlock()
+	writerValidators[typ] = validator
+}
+
+// validateWriterOptions validate options of the writer type, without creating the writer
+func validateWriterOptions(typ string, options json.RawMessage) error {
+	writerFactoriesLock.RLock()
+	validator := writerValidators[typ]
+	writerFactoriesLock.RUnlock()
+	if validator != nil {
+		return validator(options)
+	}
+	if len(options) > 0 && !json.Valid(options) {
+		return errors.New("invalid JSON")
+	}
+	return nil
+}
+
+// NewWriter create writer of the type with options by the registered factory
+func NewWriter(typ string, options json.RawMessage) (Writer, error) {
+	writerFactoriesLock.RLock()
+	factory, ok := writerFactories[typ]
+	writerFactoriesLock.RUnlock()
+	if !ok {
+		return nil, errors.New("no writer factory for type (" + typ + ")")
+	}
+	return factory(options)
+}
+
+// hasWriterFactory report the factory of writer type is registered or not
+func hasWriterFactory(typ string) bool {
+	writerFactoriesLock.RLock()
+	defer writerFactoriesLock.RUnlock()
+	_, ok := writerFactories[typ]
+	return ok
+}
+
+// unmarshalWriterOptions unmarshal options, empty options leave v unchanged
+func unmarshalWriterOptions(options json.RawMessage, v interface{}) error {
+	if len(options) == 0 {
+		return nil
+	}
+	return json.Unmarshal(options, v)
+}
+
+// decodeConsoleWriterOptions unmarshal and validate console writer options
+func decodeConsoleWriterOptions(options json.RawMessage) (opts ConsoleWriterOptions, err error) {
+	if err = unmarshalWriterOptions(options, &opts); err != nil {
+		return opts, err
+	}
+	return opts, opts.validate()
+}
+
+// decodeFileWriterOptions unmarshal and validate file writer options
+func decodeFileWriterOptions(options json.RawMessage) (opts FileWriterOptions, err error) {
+	if err = unmarshalWriterOptions(options, &opts); err != nil {
+		return opts, err
+	}
+	return opts, opts.validate()
+}
+
+func newConsoleWriterFromOptions(options json.RawMessage) (Writer, error) {
+	opts, err := decodeConsoleWriterOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return newConsoleWriter(opts, GlobalLevel, WriterTypeConsole), nil
+}
+
+func newFileWriterFromOptions(options json.RawMessage) (Writer, error) {
+	opts, err := decodeFileWriterOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	return newFileWriter(opts, GlobalLevel, WriterTypeFile), nil
+}
+
+func init() {
+	RegisterWriterFactory(WriterTypeConsole, newConsoleWriterFromOptions)
+	RegisterWriterFactory(WriterTypeFile, newFileWriterFromOptions)
+	RegisterWriterValidator(WriterTypeConsole, func(options json.RawMessage) error {
+		_, err := decodeConsoleWriterOptions(options)
+		return err
+	})
+	RegisterWriterValidator(WriterTypeFile, func(options json.RawMessage) error {
+		_, err := decodeFileWriterOptions(options)
+		return err
+	})
+}
@@ -0,0 +1,127 @@
+package golog
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memoryWriter struct {
+	lock   sync.Mutex
+	prefix string
+	lines  []string
+}
+
+func (w *memoryWriter) Init() error {
+	return nil
+}
+
+func (w *memoryWriter) Write(r *Record) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.lines = append(w.lines, w.prefix+LevelFlags[r.Level()]+" "+r.Msg())
+	return nil
+}
+
+func Test_RegisterWriterFactory(t *testing.T) {
+	var created *memoryWriter
+	RegisterWriterFactory("memory", func(options json.RawMessage) (Writer, error) {
+		var opts struct {
+			Prefix string `json:"prefix"`
+		}
+		if err := json.Unmarshal(options, &opts); err != nil {
+			return nil, err
+		}
+		created = &memoryWriter{prefix: opts.Prefix}
+		return created, nil
+	})
+
+	dir := t.TempDir()
+	config := `{
+  "level": "common",
+  "writers": [
+    {"type": "memory", "name": "mem", "options": {"prefix": "mem: "}},
+    {"type": "file", "name": "errors", "options": {"filename": "` + dir + `/error.log", "level": "error"}},
+    {"type": "console", "name": "stdout", "options": {"level": "access"}}
+  ]
+}`
+	var lc LogConfig
+	if err := json.Unmarshal([]byte(config), &lc); err != nil {
+		t.Fatal(err)
+	}
+	lg := newLoggerWithRecords(make(chan *Record, uint(128)))
+	if err := setupLogger(lg, lc); err != nil {
+		t.Fatal(err)
+	}
+	if len(lg.writers) != 3 {
+		t.Fatalf("got %d writers, want 3", len(lg.writers))
+	}
+	lg.Error("factory error")
+	lg.Common("factory common")
+	lg.Close()
+
+	if created == nil || len(created.lines) != 2 || created.lines[0] != "mem: ERROR factory error" {
+		t.Errorf("memory writer lines %#v", created)
+	}
+	cnt, err := ioutil.ReadFile(dir + "/error.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(cnt), "factory error") || strings.Contains(string(cnt), "factory common") {
+		t.Errorf("error.log got %q", cnt)
+	}
+}
+
+func Test_NewWriterWithUnknownType(t *testing.T) {
+	if _, err := NewWriter("unknown", nil); err == nil {
+		t.Error("unknown writer type should fail")
+	}
+
+	lc := LogConfig{Writers: []WriterConfig{{Type: "unknown", Name: "bad"}}}
+	lg := newLoggerWithRecords(make(chan *Record, uint(1)))
+	defer lg.Close()
+	err := setupLogger(lg, lc)
+	if err == nil || !strings.HasPrefix(err.Error(), "writers[0].type: ") {
+		t.Errorf("setup with unknown writer type err %v", err)
+	}
+	if len(lg.writers) != 0 {
+		t.Errorf("no writer should be registered, got %d", len(lg.writers))
+	}
+}
+
+func Test_ValidateWithoutCreatingWriters(t *testing.T) {
+	created := 0
+	RegisterWriterFactory("counted", func(json.RawMessage) (Writer, error) {
+		created++
+		return &memoryWriter{}, nil
+	})
+	RegisterWriterValidator("counted", func(options json.RawMessage) error {
+		var opts struct {
+			Prefix string `json:"prefix"`
+		}
+		return json.Unmarshal(options, &opts)
+	})
+
+	lc := LogConfig{Writers: []WriterConfig{{Type: "counted", Options: json.RawMessage(`{"prefix": 1}`)}}}
+	if err := lc.Validate(); err == nil || !strings.HasPrefix(err.Error(), "writers[0].options: ") {
+		t.Errorf("validate err %v", err)
+	}
+	lc.Writers[0].Options = json.RawMessage(`{"prefix": "p"}`)
+	if err := lc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if created != 0 {
+		t.Errorf("validate created %d writers", created)
+	}
+
+	lg := NewLoggerWithChanSize(16)
+	defer lg.Close()
+	if err := setupLogger(lg, lc); err != nil {
+		t.Fatal(err)
+	}
+	if created != 1 {
+		t.Errorf("setup created %d writers, want 1", created)
+	}
+}